@@ -0,0 +1,580 @@
+package earcut
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ConstraintError is returned by EarcutConstrained for a segment that
+// can't be used.
+type ConstraintError struct {
+	// Segment is the index of the segment in the segments passed in.
+	Segment int
+	Msg     string
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("constraint segment %d %s", e.Segment, e.Msg)
+}
+
+// EarcutConstrained triangulates a polygon so that the given segments,
+// such as roads or property lines inside a land parcel, appear as triangle
+// edges, and no triangle crosses them.
+//
+// The data, holeIndices and dim are as for Earcut.  points holds any new
+// vertices the segments need, with dim values each, and segments holds
+// pairs of vertex indices into data followed by points; so the first
+// point is vertex len(data)/dim.  EarcutConstrained returns the triangle
+// indices along with the vertex array they refer to: data, then points,
+// then a vertex for each place where two segments cross, with values
+// beyond x and y interpolated along the first of them.
+//
+// Segments may end anywhere inside the polygon or on its boundary, and may
+// touch or cross each other.  A point on a ring edge becomes a new vertex
+// of that ring, and a point that isn't used by any segment is kept as a
+// Steiner point (see EarcutSteiner).  A segment that leaves the polygon,
+// runs along its boundary, or overlaps another segment gets a
+// *ConstraintError, and a point outside the polygon a *SteinerPointError.
+//
+// Segments between vertices on the rings split the polygon in two along
+// that diagonal, the same way Earcut bridges a hole to the outer ring.
+// Points are then added to the triangles, each splitting the triangle or
+// edge it lies in, and the other segments are flipped into place.
+func EarcutConstrained(data []float64, holeIndices []int, dim int, points []float64, segments []int) ([]int, []float64, error) {
+	if dim < 2 {
+		return nil, nil, fmt.Errorf("need at least 2 dimensions")
+	}
+	if len(points)%dim != 0 {
+		return nil, nil, fmt.Errorf("point array length %d is not a multiple of %d", len(points), dim)
+	}
+	if len(segments)%2 != 0 {
+		return nil, nil, fmt.Errorf("segment index count %d is not a multiple of 2", len(segments))
+	}
+	n := len(data) / dim
+	c := &constrainer{
+		data:        make([]float64, 0, n*dim+len(points)),
+		holeIndices: holeIndices,
+		dim:         dim,
+		n:           n,
+	}
+	c.data = append(c.data, data[:n*dim]...)
+	c.data = append(c.data, points...)
+	c.rings = c.data[:n*dim]
+	segs, err := c.prepare(segments)
+	if err != nil {
+		return nil, nil, err
+	}
+	triangles, err := c.triangulate(segs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return triangles, c.data, nil
+}
+
+// constrainer holds the state of EarcutConstrained
+type constrainer struct {
+	data        []float64
+	holeIndices []int
+	dim         int
+
+	// n is the number of ring vertices, which come first in data, and
+	// rings is that part of data
+	n     int
+	rings []float64
+
+	// canon maps each vertex to the first vertex with the same x and y
+	canon []int
+	seen  map[[2]float64]int
+
+	// loose holds the points that are in no segment
+	loose []int
+
+	// tol is how far off a segment rounding can put a vertex
+	tol float64
+}
+
+// a constraint segment after splitting, with the index of the input
+// segment it came from
+type constraint struct {
+	a, b int
+	src  int
+}
+
+func (c *constrainer) xy(v int) (float64, float64) {
+	return c.data[v*c.dim], c.data[v*c.dim+1]
+}
+
+// on reports whether (x, y) lies on the segment from a to b, to within
+// rounding for the size of the coordinates
+func (c *constrainer) on(ax, ay, bx, by, x, y float64) bool {
+	if onSegment(ax, ay, bx, by, x, y) {
+		return true
+	}
+	if x < math.Min(ax, bx)-c.tol || x > math.Max(ax, bx)+c.tol || y < math.Min(ay, by)-c.tol || y > math.Max(ay, by)+c.tol {
+		return false
+	}
+	return math.Abs(orient2d(ax, ay, bx, by, x, y)) <= c.tol*math.Hypot(bx-ax, by-ay)
+}
+
+// addCanon registers a new vertex, or maps it to an earlier vertex at the
+// same place
+func (c *constrainer) addCanon(v int) {
+	key := [2]float64{c.data[v*c.dim], c.data[v*c.dim+1]}
+	if u, ok := c.seen[key]; ok {
+		c.canon = append(c.canon, u)
+	} else {
+		c.seen[key] = v
+		c.canon = append(c.canon, v)
+	}
+}
+
+// prepare checks the points and segments, and splits the segments
+// wherever they cross or touch each other or the rings, so that no two
+// segments overlap and no vertex lies inside a segment
+func (c *constrainer) prepare(segments []int) ([]constraint, error) {
+	total := len(c.data) / c.dim
+	c.seen = map[[2]float64]int{}
+	for v := 0; v < total; v++ {
+		c.addCanon(v)
+		x, y := c.xy(v)
+		c.tol = math.Max(c.tol, math.Max(math.Abs(x), math.Abs(y)))
+	}
+	c.tol *= 1e-12
+	for v := c.n; v < total; v++ {
+		x, y := c.xy(v)
+		if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+			return nil, &SteinerPointError{Index: v - c.n, Msg: "is not a finite point"}
+		}
+		if c.canon[v] == v && !onRings(c.rings, c.holeIndices, c.dim, x, y) &&
+			!polygonContains(c.rings, c.holeIndices, c.dim, x, y) {
+			return nil, &SteinerPointError{Index: v - c.n, Msg: "is outside the polygon"}
+		}
+	}
+
+	// ring edges, by canonical vertex
+	ringEdges := map[[2]int]bool{}
+	for i, l := 0, len(c.holeIndices); i <= l; i++ {
+		start, end := ringRange(c.rings, c.holeIndices, c.dim, i)
+		for j, k := start/c.dim, end/c.dim-1; j < end/c.dim; j++ {
+			ringEdges[[2]int{c.canon[k], c.canon[j]}] = true
+			ringEdges[[2]int{c.canon[j], c.canon[k]}] = true
+			k = j
+		}
+	}
+
+	segs := make([]constraint, len(segments)/2)
+	for k := range segs {
+		a, b := segments[k*2], segments[k*2+1]
+		if a < 0 || a >= total || b < 0 || b >= total {
+			return nil, &ConstraintError{Segment: k, Msg: "has a vertex index out of range"}
+		}
+		segs[k] = constraint{c.canon[a], c.canon[b], k}
+		if segs[k].a == segs[k].b {
+			return nil, &ConstraintError{Segment: k, Msg: "has zero length"}
+		}
+		if c.crossesRings(segs[k]) {
+			return nil, &ConstraintError{Segment: k, Msg: "crosses the polygon boundary"}
+		}
+	}
+
+	// add a vertex where each pair of segments cross
+	for k := range segs {
+		for l := k + 1; l < len(segs); l++ {
+			if err := c.intersect(segs[k], segs[l]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// split each segment at every vertex inside it
+	total = len(c.data) / c.dim
+	used := make([]bool, total)
+	split := []constraint{}
+	done := map[[2]int]bool{}
+	for _, s := range segs {
+		ax, ay := c.xy(s.a)
+		bx, by := c.xy(s.b)
+		type stop struct {
+			v int
+			t float64
+		}
+		stops := []stop{{s.a, 0}, {s.b, 1}}
+		for v := 0; v < total; v++ {
+			if c.canon[v] != v || v == s.a || v == s.b {
+				continue
+			}
+			x, y := c.xy(v)
+			if c.on(ax, ay, bx, by, x, y) {
+				t := ((x-ax)*(bx-ax) + (y-ay)*(by-ay)) / ((bx-ax)*(bx-ax) + (by-ay)*(by-ay))
+				stops = append(stops, stop{v, t})
+			}
+		}
+		sort.Slice(stops, func(i, j int) bool { return stops[i].t < stops[j].t })
+		for i := 1; i < len(stops); i++ {
+			a, b := stops[i-1].v, stops[i].v
+			if done[[2]int{a, b}] || ringEdges[[2]int{a, b}] {
+				continue
+			}
+			done[[2]int{a, b}] = true
+			done[[2]int{b, a}] = true
+			x0, y0 := c.xy(a)
+			x1, y1 := c.xy(b)
+			mx, my := (x0+x1)/2, (y0+y1)/2
+			if onRings(c.rings, c.holeIndices, c.dim, mx, my) {
+				return nil, &ConstraintError{Segment: s.src, Msg: "runs along the polygon boundary"}
+			}
+			if !polygonContains(c.rings, c.holeIndices, c.dim, mx, my) {
+				return nil, &ConstraintError{Segment: s.src, Msg: "is outside the polygon"}
+			}
+			used[a], used[b] = true, true
+			split = append(split, constraint{a, b, s.src})
+		}
+	}
+	for v := c.n; v < total; v++ {
+		if c.canon[v] == v && !used[v] {
+			c.loose = append(c.loose, v)
+		}
+	}
+	return split, nil
+}
+
+// crossesRings reports whether a segment properly crosses a ring edge
+func (c *constrainer) crossesRings(s constraint) bool {
+	ax, ay := c.xy(s.a)
+	bx, by := c.xy(s.b)
+	for i, l := 0, len(c.holeIndices); i <= l; i++ {
+		start, end := ringRange(c.rings, c.holeIndices, c.dim, i)
+		for j, k := start/c.dim, end/c.dim-1; j < end/c.dim; j++ {
+			px, py := c.xy(k)
+			qx, qy := c.xy(j)
+			if crosses(ax, ay, bx, by, px, py, qx, qy) {
+				return true
+			}
+			k = j
+		}
+	}
+	return false
+}
+
+// intersect adds a vertex where two segments cross, or returns an error if
+// they overlap
+func (c *constrainer) intersect(s, t constraint) error {
+	ax, ay := c.xy(s.a)
+	bx, by := c.xy(s.b)
+	px, py := c.xy(t.a)
+	qx, qy := c.xy(t.b)
+	d1 := orient2d(ax, ay, bx, by, px, py)
+	d2 := orient2d(ax, ay, bx, by, qx, qy)
+	if d1 == 0 && d2 == 0 {
+		// collinear; they overlap if either contains part of the other
+		if onSegment(ax, ay, bx, by, (px+qx)/2, (py+qy)/2) ||
+			onSegment(px, py, qx, qy, (ax+bx)/2, (ay+by)/2) ||
+			(onSegment(ax, ay, bx, by, px, py) && t.a != s.a && t.a != s.b) ||
+			(onSegment(ax, ay, bx, by, qx, qy) && t.b != s.a && t.b != s.b) {
+			return &ConstraintError{Segment: t.src, Msg: fmt.Sprintf("overlaps segment %d", s.src)}
+		}
+		return nil
+	}
+	if !crosses(ax, ay, bx, by, px, py, qx, qy) {
+		return nil
+	}
+	// where several segments cross at one place, rounding puts each
+	// crossing somewhere slightly different, so reuse any vertex that's
+	// already on both
+	for u := 0; u < len(c.canon); u++ {
+		if x, y := c.xy(u); c.canon[u] == u && c.on(ax, ay, bx, by, x, y) && c.on(px, py, qx, qy, x, y) {
+			return nil
+		}
+	}
+	f := d1 / (d1 - d2)
+	x := px + (qx-px)*f
+	y := py + (qy-py)*f
+	if _, ok := c.seen[[2]float64{x, y}]; ok {
+		return nil
+	}
+	// interpolate along s
+	g := ((x-ax)*(bx-ax) + (y-ay)*(by-ay)) / ((bx-ax)*(bx-ax) + (by-ay)*(by-ay))
+	v := len(c.data) / c.dim
+	c.data = append(c.data, x, y)
+	for k := 2; k < c.dim; k++ {
+		c.data = append(c.data, c.data[s.a*c.dim+k]*(1-g)+c.data[s.b*c.dim+k]*g)
+	}
+	c.addCanon(v)
+	return nil
+}
+
+// crosses reports whether segments ab and pq cross at a single point
+// inside both of them
+func crosses(ax, ay, bx, by, px, py, qx, qy float64) bool {
+	d1 := orient2d(ax, ay, bx, by, px, py)
+	d2 := orient2d(ax, ay, bx, by, qx, qy)
+	d3 := orient2d(px, py, qx, qy, ax, ay)
+	d4 := orient2d(px, py, qx, qy, bx, by)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// triangulate builds the polygon, splits it along the constraints between
+// ring vertices, and runs the ear slicing loop on each piece.  It then adds
+// the other vertices the constraints need, and flips in the remaining
+// constraints.
+func (c *constrainer) triangulate(segs []constraint) ([]int, error) {
+	dim := c.dim
+	outerLen := c.n * dim
+	if len(c.holeIndices) > 0 {
+		outerLen = c.holeIndices[0] * dim
+	}
+	outerNode := linkedList(c.data, 0, outerLen, dim, true)
+	triangles := []int{}
+	if outerNode == nil {
+		return triangles, nil
+	}
+
+	// holes with one vertex are added later, with the points inside
+	queue := []*node{}
+	for i := range c.holeIndices {
+		start, end := ringRange(c.rings, c.holeIndices, dim, i+1)
+		if list := linkedList(c.data, start, end, dim, false); list != nil && list != list.next {
+			queue = append(queue, getLeftmost(list))
+		}
+	}
+	if len(queue) > 0 {
+		outerNode = eliminateHoleQueue(queue, outerNode, nil)
+	}
+
+	pieces := []*node{outerNode}
+	rest := []constraint{}
+	for _, s := range segs {
+		if s.a >= c.n || s.b >= c.n || !c.split(s, &pieces) {
+			rest = append(rest, s)
+		}
+	}
+
+	// as in EarcutT, use the z-order curve hash for larger shapes
+	var minX, minY, invSize float64
+	if len(c.rings) > 80*dim {
+		minX, minY = math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for i := 0; i < outerLen; i += dim {
+			minX = math.Min(minX, c.data[i])
+			minY = math.Min(minY, c.data[i+1])
+			maxX = math.Max(maxX, c.data[i])
+			maxY = math.Max(maxY, c.data[i+1])
+		}
+		invSize = math.Max(maxX-minX, maxY-minY)
+		if invSize != 0.0 {
+			invSize = 1.0 / invSize
+		}
+	}
+	for _, piece := range pieces {
+		earcutLinked(piece, &triangles, dim, minX, minY, invSize, 0)
+	}
+
+	// the vertices still missing are the points and any ring vertices
+	// filtered out as collinear or as holes with one vertex.  Steiner
+	// points from the ear slicing loop don't always come out right when
+	// several lie in a line, so these are added to the triangles instead,
+	// each splitting the triangle or edge it lies in.
+	used := make([]bool, len(c.data)/dim)
+	for _, v := range triangles {
+		used[c.canon[v]] = true
+	}
+	missing := []int{}
+	for _, s := range segs {
+		for _, v := range []int{s.a, s.b} {
+			if !used[v] {
+				used[v] = true
+				missing = append(missing, v)
+			}
+		}
+	}
+	for _, v := range c.loose {
+		if !used[v] {
+			used[v] = true
+			missing = append(missing, v)
+		}
+	}
+	for i := range c.holeIndices {
+		start, end := ringRange(c.rings, c.holeIndices, dim, i+1)
+		if v := c.canon[start/dim]; end-start == dim && !used[v] {
+			used[v] = true
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 && len(rest) == 0 {
+		return triangles, nil
+	}
+
+	m := newMesh(c.data, dim, triangles)
+	m.fixRings(c.rings, c.holeIndices, dim)
+	for _, s := range segs {
+		m.fix(s.a, s.b)
+	}
+	r := &refiner{cdt: m, data: c.data, dim: dim, orient: m.orientation()}
+	for _, v := range missing {
+		if !r.insert(v) {
+			return nil, fmt.Errorf("vertex %d can't be added to the triangulation", v)
+		}
+	}
+	// the pieces weren't split along constraints with a point at either
+	// end, or where the bridge to a hole was in the way
+	for _, s := range rest {
+		if !r.recover(s.a, s.b) {
+			return nil, &ConstraintError{Segment: s.src, Msg: "can't be made a triangle edge"}
+		}
+	}
+	return m.he.Triangles, nil
+}
+
+// insert adds a vertex that's in data but not yet in the triangles, by
+// splitting the triangle or edge it lies in
+func (r *refiner) insert(v int) bool {
+	p := r.nodes[v]
+	t, on := -1, -1
+	if len(r.he.Triangles) > 0 {
+		t, on = r.locate(0, p.x, p.y)
+	}
+	if t < 0 {
+		// the walk was stopped by a segment, so try every triangle
+		t, on = r.find(p.x, p.y)
+	}
+	if t < 0 {
+		return false
+	}
+	if on >= 0 {
+		r.splitEdge(on, v)
+	} else {
+		r.splitTriangle(t, v)
+	}
+	return true
+}
+
+// find returns the first half-edge of the triangle containing the point
+// (x, y), and the half-edge it lies on or -1, as for locate
+func (r *refiner) find(x, y float64) (int, int) {
+	tri := r.he.Triangles
+	for t := 0; t < len(tri); t += 3 {
+		on := -1
+		for k := 0; k < 3; k++ {
+			h := t + k
+			a, b := r.nodes[tri[h]], r.nodes[tri[r.he.Next(h)]]
+			o := orient2d(a.x, a.y, b.x, b.y, x, y) * r.orient
+			l2 := (b.x-a.x)*(b.x-a.x) + (b.y-a.y)*(b.y-a.y)
+			if math.Abs(o) <= l2*1e-12 {
+				on = h
+			} else if o < 0 {
+				on = -2
+				break
+			}
+		}
+		if on > -2 {
+			return t, on
+		}
+	}
+	return -1, -1
+}
+
+// nodesAt returns the nodes of a ring at a vertex's position
+func (c *constrainer) nodesAt(ring *node, v int) []*node {
+	x, y := c.xy(v)
+	nodes := []*node{}
+	p := ring
+	for {
+		if p.x == x && p.y == y {
+			nodes = append(nodes, p)
+		}
+		p = p.next
+		if p == ring {
+			break
+		}
+	}
+	return nodes
+}
+
+// split cuts the piece of the polygon that holds both ends of a segment in
+// two along it, the same way a hole is bridged to the outer ring.  It
+// reports whether the segment is now an edge of a piece.
+func (c *constrainer) split(s constraint, pieces *[]*node) bool {
+	for i, piece := range *pieces {
+		for _, a := range c.nodesAt(piece, s.a) {
+			for _, b := range c.nodesAt(piece, s.b) {
+				if equals(a.next, b) || equals(a.prev, b) {
+					return true
+				}
+				if isValidDiagonal(a, b) {
+					// keep the copies of both ends, like the originals
+					b2 := splitPolygon(a, b)
+					b2.steiner = true
+					b2.next.steiner = true
+					(*pieces)[i] = a
+					*pieces = append(*pieces, b2)
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// recover flips the edges that cross the segment from vertex a to vertex
+// b until there are none, so that it is an edge.  It reports false if a
+// segment or the boundary is in the way, or the flips don't converge.
+func (r *refiner) recover(a, b int) bool {
+	m := r.cdt
+	pa, pb := m.nodes[a], m.nodes[b]
+	// vertices this close to the segment's line split it in prepare, so
+	// edges from them don't cross it
+	eps := ((pb.x-pa.x)*(pb.x-pa.x) + (pb.y-pa.y)*(pb.y-pa.y)) * 1e-12
+	for limit := len(m.he.Triangles); limit > 0; limit-- {
+		tri := m.he.Triangles
+		found, changed := false, false
+		for h := range tri {
+			t := m.he.Twin[h]
+			if t >= 0 && t < h {
+				continue
+			}
+			// count the segment as crossing an edge it ends on, as
+			// rounding can leave a vertex on the far edge of its own
+			// triangle
+			p, q := m.nodes[m.he.Origin(h)], m.nodes[m.he.Dest(h)]
+			d1 := orient2d(pa.x, pa.y, pb.x, pb.y, p.x, p.y)
+			d2 := orient2d(pa.x, pa.y, pb.x, pb.y, q.x, q.y)
+			d3 := orient2d(p.x, p.y, q.x, q.y, pa.x, pa.y)
+			d4 := orient2d(p.x, p.y, q.x, q.y, pb.x, pb.y)
+			if !((d1 > eps && d2 < -eps) || (d1 < -eps && d2 > eps)) || (d3 > 0 && d4 > 0) || (d3 < 0 && d4 < 0) {
+				continue
+			}
+			found = true
+			if t < 0 || m.isFixed(h) {
+				if d3 != 0 && d4 != 0 {
+					return false
+				}
+				// where a ring touches itself, the ear slicing loop
+				// may filter a vertex out of one side only, leaving
+				// it on an edge there
+				v := a
+				if d4 == 0 {
+					v = b
+				}
+				r.splitEdge(h, v)
+				changed = true
+				break
+			}
+			// only flip where the new diagonal is inside the
+			// quadrilateral
+			c, d := m.nodes[tri[m.he.Prev(h)]], m.nodes[tri[m.he.Prev(t)]]
+			if orient2d(c.x, c.y, p.x, p.y, d.x, d.y)*r.orient > 0 && orient2d(d.x, d.y, q.x, q.y, c.x, c.y)*r.orient > 0 {
+				m.flip(h)
+				changed = true
+			}
+		}
+		if !found {
+			return true
+		}
+		if !changed {
+			return false
+		}
+	}
+	return false
+}
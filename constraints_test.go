@@ -0,0 +1,247 @@
+package earcut
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// checkConstrained checks that a constrained triangulation covers the
+// polygon, and that every segment is made of triangle edges
+func checkConstrained(name string, data []float64, holeIndices []int, dim int, segs [][4]float64, tri []int, all []float64, t *testing.T) {
+	exp := polygonArea(data, holeIndices, dim)
+	if a := trianglesArea(all, dim, tri); math.Abs(a-exp) > exp*1e-9 {
+		t.Errorf("Expected area %f, got %f for %s", exp, a, name)
+	}
+	edges := map[[4]float64]bool{}
+	used := map[int]bool{}
+	for i := 0; i < len(tri); i += 3 {
+		for k := 0; k < 3; k++ {
+			a, b := tri[i+k], tri[i+(k+1)%3]
+			used[a] = true
+			edges[[4]float64{all[a*dim], all[a*dim+1], all[b*dim], all[b*dim+1]}] = true
+			edges[[4]float64{all[b*dim], all[b*dim+1], all[a*dim], all[a*dim+1]}] = true
+		}
+	}
+	// vertices off a segment by rounding are taken as on it, as in
+	// EarcutConstrained
+	c := &constrainer{}
+	for i := 0; i+1 < len(all); i += dim {
+		c.tol = math.Max(c.tol, math.Max(math.Abs(all[i]), math.Abs(all[i+1])))
+	}
+	c.tol *= 1e-12
+	for k, s := range segs {
+		ts := []float64{}
+		pts := map[float64][2]float64{}
+		for v := range used {
+			x, y := all[v*dim], all[v*dim+1]
+			if c.on(s[0], s[1], s[2], s[3], x, y) {
+				f := ((x-s[0])*(s[2]-s[0]) + (y-s[1])*(s[3]-s[1])) / ((s[2]-s[0])*(s[2]-s[0]) + (s[3]-s[1])*(s[3]-s[1]))
+				if _, ok := pts[f]; !ok {
+					ts = append(ts, f)
+					pts[f] = [2]float64{x, y}
+				}
+			}
+		}
+		sort.Float64s(ts)
+		if len(ts) < 2 || ts[0] != 0 || ts[len(ts)-1] != 1 {
+			t.Errorf("Segment %d ends aren't triangle vertices for %s", k, name)
+			continue
+		}
+		// check each piece between the vertices on it, as a vertex where
+		// two segments cross is rounded off the line
+	pieces:
+		for i := 1; i < len(ts); i++ {
+			p, q := pts[ts[i-1]], pts[ts[i]]
+			if !edges[[4]float64{p[0], p[1], q[0], q[1]}] {
+				t.Errorf("Segment %d isn't made of triangle edges for %s: missing %v-%v", k, name, p, q)
+				break
+			}
+			for e := range edges {
+				if crosses(p[0], p[1], q[0], q[1], e[0], e[1], e[2], e[3]) {
+					t.Errorf("Segment %d crosses a triangle edge for %s", k, name)
+					break pieces
+				}
+			}
+		}
+	}
+}
+
+// segment coordinates from the vertex indices
+func segmentCoords(all []float64, dim int, segments []int) [][4]float64 {
+	segs := [][4]float64{}
+	for i := 0; i < len(segments); i += 2 {
+		a, b := segments[i]*dim, segments[i+1]*dim
+		segs = append(segs, [4]float64{all[a], all[a+1], all[b], all[b+1]})
+	}
+	return segs
+}
+
+func TestEarcutConstrained(t *testing.T) {
+	square := []float64{0, 0, 10, 0, 10, 10, 0, 10}
+	for _, tc := range []struct {
+		name     string
+		points   []float64
+		segments []int
+		verts    int
+	}{
+		{"diagonal", nil, []int{1, 3}, 4},
+		{"floating", []float64{3, 5, 7, 5}, []int{4, 5}, 6},
+		{"slit", []float64{5, 4}, []int{0, 4}, 5},
+		{"edge to edge", []float64{5, 0, 5, 10}, []int{4, 5}, 6},
+		{"crossing", []float64{2, 2, 8, 8, 2, 8, 8, 2}, []int{4, 5, 6, 7}, 9},
+		{"tee", []float64{5, 0, 5, 10, 0, 5, 5, 5}, []int{4, 5, 6, 7}, 8},
+		{"star", []float64{5, 5, 3, 1, 9, 6, 1, 8}, []int{4, 5, 4, 6, 4, 7, 4, 0}, 8},
+		{"chain", []float64{2, 2, 4, 3, 6, 2, 8, 3}, []int{4, 5, 5, 6, 6, 7}, 8},
+		{"loop", []float64{3, 3, 7, 3, 7, 7, 3, 7}, []int{4, 5, 5, 6, 6, 7, 7, 4}, 8},
+		{"loose", []float64{3, 5, 7, 5, 5, 8, 5, 0}, []int{4, 5}, 8},
+		{"through vertex", []float64{1, 1, 9, 9}, []int{0, 4, 4, 5, 5, 2}, 6},
+	} {
+		tri, all, err := EarcutConstrained(square, nil, 2, tc.points, tc.segments)
+		if err != nil {
+			t.Errorf("Error making triangles for %s: %s", tc.name, err)
+			continue
+		}
+		if len(all)/2 != tc.verts {
+			t.Errorf("Expected %d vertices, got %d for %s", tc.verts, len(all)/2, tc.name)
+		}
+		checkConstrained(tc.name, square, nil, 2, segmentCoords(all, 2, tc.segments), tri, all, t)
+		used := map[int]bool{}
+		for _, v := range tri {
+			used[v] = true
+		}
+		for v := 4; v < len(all)/2; v++ {
+			if !used[v] {
+				t.Errorf("Vertex %d isn't used for %s", v, tc.name)
+			}
+		}
+	}
+}
+
+func TestEarcutConstrainedHole(t *testing.T) {
+	data := []float64{
+		0, 0, 0, 10, 0, 0, 10, 10, 0, 0, 10, 0,
+		4, 4, 1, 6, 4, 1, 6, 6, 1, 4, 6, 1,
+	}
+	points := []float64{
+		1, 9, 2, 9, 9, 4,
+		2, 5, 8,
+		5, 2, 2, 5, 4, 1,
+	}
+	segments := []int{0, 4, 8, 9, 11, 12, 7, 2}
+	tri, all, err := EarcutConstrained(data, []int{4}, 3, points, segments)
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	// the last segment crosses the second at (8.5, 9)
+	if len(all) != len(data)+len(points)+3 {
+		t.Errorf("Expected %d values, got %d", len(data)+len(points)+3, len(all))
+	}
+	checkConstrained("hole", data, []int{4}, 3, segmentCoords(all, 3, segments), tri, all, t)
+
+	// two segments crossing at (5, 5), with z interpolated along the
+	// first
+	points = []float64{1, 1, 2, 9, 9, 4, 1, 9, 0, 9, 1, 0}
+	tri, all, err = EarcutConstrained(data[:12], nil, 3, points, []int{4, 5, 6, 7})
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	if len(all) != 12+len(points)+3 {
+		t.Fatalf("Expected a crossing vertex, got %d values", len(all))
+	}
+	if x := all[len(all)-3:]; x[0] != 5 || x[1] != 5 || x[2] != 3 {
+		t.Errorf("Crossing vertex doesn't match: %v", x)
+	}
+	checkConstrained("crossing", data[:12], nil, 3, segmentCoords(all, 3, []int{4, 5, 6, 7}), tri, all, t)
+}
+
+func TestEarcutConstrainedRounding(t *testing.T) {
+	data, holeIndices, err := loadVertices("steiner")
+	if err != nil {
+		t.Fatal("Error reading fixture data:", err)
+	}
+	// the crossing comes out just right of the vertical segment, at
+	// x = 30.000000000000007
+	points := []float64{30, 94, 79.9381569327253, 31.345791582755524}
+	segments := []int{8, 5, 9, 3}
+	tri, all, err := EarcutConstrained(data, holeIndices, 2, points, segments)
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	checkConstrained("rounding", data, holeIndices, 2, segmentCoords(all, 2, segments), tri, all, t)
+}
+
+func TestEarcutConstrainedInvalid(t *testing.T) {
+	data := []float64{0, 0, 10, 0, 10, 10, 0, 10, 4, 4, 6, 4, 6, 6, 4, 6}
+	holes := []int{4}
+	for _, tc := range []struct {
+		name     string
+		points   []float64
+		segments []int
+		segment  int
+	}{
+		{"across hole", []float64{2, 5, 8, 5}, []int{8, 9}, 0},
+		{"along edge", []float64{5, 0}, []int{0, 8}, 0},
+		{"overlap", []float64{1, 1, 3, 3, 2, 2, 3.5, 3.5}, []int{8, 9, 10, 11}, 1},
+		{"zero length", []float64{1, 1, 1, 1}, []int{8, 9}, 0},
+		{"out of range", []float64{1, 1}, []int{1, 8, 8, 9}, 1},
+	} {
+		_, _, err := EarcutConstrained(data, holes, 2, tc.points, tc.segments)
+		cerr, ok := err.(*ConstraintError)
+		if !ok {
+			t.Errorf("Expected a ConstraintError for %s, got %v", tc.name, err)
+			continue
+		}
+		if cerr.Segment != tc.segment {
+			t.Errorf("Expected error for segment %d for %s, got %d (%s)", tc.segment, tc.name, cerr.Segment, cerr)
+		}
+	}
+	for _, points := range [][]float64{{1, 1, 11, 1}, {1, 1, 5, 5}} {
+		_, _, err := EarcutConstrained(data, holes, 2, points, []int{8, 9})
+		if serr, ok := err.(*SteinerPointError); !ok || serr.Index != 1 {
+			t.Errorf("Expected a SteinerPointError for point 1 at %v, got %v", points[2:], err)
+		}
+	}
+}
+
+// testConstrainedFixture adds segments from the centroid of some of the
+// triangles to their corners and between neighbouring centroids
+func testConstrainedFixture(name string, t *testing.T) {
+	flat, holeIndices, err := loadVertices(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := Earcut(flat, holeIndices, 2)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	n := len(flat) / 2
+	points := []float64{}
+	segments := []int{}
+	step := len(orig)/3/20 + 1
+	for i := 0; i < len(orig)/3; i += step {
+		a, b, c := orig[i*3], orig[i*3+1], orig[i*3+2]
+		cx := (flat[a*2] + flat[b*2] + flat[c*2]) / 3
+		cy := (flat[a*2+1] + flat[b*2+1] + flat[c*2+1]) / 3
+		if orient2d(flat[a*2], flat[a*2+1], flat[b*2], flat[b*2+1], flat[c*2], flat[c*2+1]) == 0 {
+			continue
+		}
+		v := n + len(points)/2
+		points = append(points, cx, cy)
+		segments = append(segments, v, a)
+		if i%2 == 0 {
+			segments = append(segments, v, b)
+		}
+	}
+	tri, all, err := EarcutConstrained(flat, holeIndices, 2, points, segments)
+	if err != nil {
+		t.Fatalf("Error making triangles for %s: %s", name, err)
+	}
+	checkConstrained(name, flat, holeIndices, 2, segmentCoords(all, 2, segments), tri, all, t)
+}
+
+func TestEarcutConstrainedFixtures(t *testing.T) {
+	for _, name := range []string{"building", "dude", "water2", "water3", "water3b", "water4", "hole-touching-outer", "steiner"} {
+		testConstrainedFixture(name, t)
+	}
+}
@@ -0,0 +1,187 @@
+package earcut
+
+import (
+	"math"
+)
+
+// EarcutDelaunay triangulates a polygon with Earcut, then improves the
+// triangles with Delaunay.  The arguments are as for Earcut.
+func EarcutDelaunay(data []float64, holeIndices []int, dim int) ([]int, error) {
+	triangles, err := Earcut(data, holeIndices, dim)
+	if err != nil {
+		return nil, err
+	}
+	return Delaunay(data, holeIndices, dim, triangles), nil
+}
+
+// Delaunay flips the internal diagonals of a triangulation until it is a
+// constrained Delaunay triangulation of the polygon: no triangle's
+// circumcircle contains a vertex of the triangle across any of its
+// internal edges.  This removes most of the long thin triangles that ear
+// clipping tends to leave, without adding any vertices.
+//
+// Edges that lie along the input rings are never flipped, so the outline
+// of the polygon and its holes is kept.  The arguments are as for
+// Deviation; a new index list is returned, covering the same area with
+// the same number of triangles, and the input is left unchanged.
+//
+// Flips are made in a fixed order, so the result is deterministic.
+func Delaunay(data []float64, holeIndices []int, dim int, triangles []int) []int {
+	m := newCDT(data, holeIndices, dim, triangles)
+	stack := make([]int, len(triangles))
+	for h := range stack {
+		stack[h] = len(triangles) - 1 - h
+	}
+	m.legalize(stack)
+	return m.he.Triangles
+}
+
+// cdt is a triangulation that is edited in place, along with the ring
+// segments that have to stay as edges.  Vertices are kept as nodes, so
+// that inserted vertices can be marked as Steiner points.
+type cdt struct {
+	nodes []*node
+	he    *HalfEdges
+
+	// weld maps each vertex to the first vertex with the same
+	// coordinates, and fixed holds the welded ends of every segment,
+	// both ways round
+	weld  []int
+	fixed map[[2]int]bool
+}
+
+func newCDT(data []float64, holeIndices []int, dim int, triangles []int) *cdt {
+	m := newMesh(data, dim, triangles)
+	m.fixRings(data, holeIndices, dim)
+	return m
+}
+
+// newMesh builds a cdt with no segments
+func newMesh(data []float64, dim int, triangles []int) *cdt {
+	tri := make([]int, len(triangles))
+	copy(tri, triangles)
+	m := &cdt{
+		nodes: make([]*node, len(data)/dim),
+		he:    NewWeldedHalfEdges(data, dim, tri),
+		weld:  weldVertices(data, dim),
+		fixed: map[[2]int]bool{},
+	}
+	for v := range m.nodes {
+		m.nodes[v] = newNode(v*dim, data[v*dim], data[v*dim+1])
+	}
+	return m
+}
+
+// fixRings marks the edges of the rings as segments; data may be just the
+// start of the vertices in the mesh
+func (m *cdt) fixRings(data []float64, holeIndices []int, dim int) {
+	for i, l := 0, len(holeIndices); i <= l; i++ {
+		start, end := ringRange(data, holeIndices, dim, i)
+		for j, k := start/dim, end/dim-1; j < end/dim; j++ {
+			m.fix(k, j)
+			k = j
+		}
+	}
+}
+
+// fix marks the edge from a to b as a segment
+func (m *cdt) fix(a, b int) {
+	m.fixed[[2]int{m.weld[a], m.weld[b]}] = true
+	m.fixed[[2]int{m.weld[b], m.weld[a]}] = true
+}
+
+// unfix removes the segment from a to b
+func (m *cdt) unfix(a, b int) {
+	delete(m.fixed, [2]int{m.weld[a], m.weld[b]})
+	delete(m.fixed, [2]int{m.weld[b], m.weld[a]})
+}
+
+// isFixed reports whether half-edge h lies along a segment
+func (m *cdt) isFixed(h int) bool {
+	return m.fixed[[2]int{m.weld[m.he.Origin(h)], m.weld[m.he.Dest(h)]}]
+}
+
+// link makes half-edges a and b twins; b may be -1
+func (m *cdt) link(a, b int) {
+	m.he.Twin[a] = b
+	if b >= 0 {
+		m.he.Twin[b] = a
+	}
+}
+
+// legalize flips the half-edges on the stack, and the edges around them
+// in turn, until they are all locally Delaunay
+func (m *cdt) legalize(stack []int) {
+	tri := m.he.Triangles
+	// Lawson's algorithm terminates on its own; the limit only guards
+	// against rounding making a pair of triangles flip back and forth
+	limit := len(tri) * len(tri)
+	for len(stack) > 0 && limit > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		t := m.he.Twin[h]
+		if t < 0 || m.isFixed(h) {
+			continue
+		}
+		a, b, c := m.nodes[tri[h]], m.nodes[tri[m.he.Next(h)]], m.nodes[tri[m.he.Prev(h)]]
+		d := m.nodes[tri[m.he.Prev(t)]]
+		orient := orient2d(a.x, a.y, b.x, b.y, c.x, c.y)
+		if orient == 0 || !inCircle(a.x, a.y, b.x, b.y, c.x, c.y, d.x, d.y, orient) {
+			continue
+		}
+		// the new diagonal c-d must lie inside the quadrilateral
+		if orient2d(c.x, c.y, a.x, a.y, d.x, d.y)*orient <= 0 || orient2d(d.x, d.y, b.x, b.y, c.x, c.y)*orient <= 0 {
+			continue
+		}
+		limit--
+		stack = append(stack, m.flip(h)...)
+	}
+}
+
+// flip replaces the edge a-b of triangles abc and bad with the edge c-d,
+// where h is the half-edge from a to b.  It returns the four outer edges
+// of the two new triangles.
+func (m *cdt) flip(h int) []int {
+	tri := m.he.Triangles
+	t := m.he.Twin[h]
+	a, b, c := tri[h], tri[m.he.Next(h)], tri[m.he.Prev(h)]
+	d := tri[m.he.Prev(t)]
+	b1 := h - h%3
+	b2 := t - t%3
+	twBC, twCA := m.he.Twin[m.he.Next(h)], m.he.Twin[m.he.Prev(h)]
+	twAD, twDB := m.he.Twin[m.he.Next(t)], m.he.Twin[m.he.Prev(t)]
+
+	tri[b1], tri[b1+1], tri[b1+2] = c, a, d
+	tri[b2], tri[b2+1], tri[b2+2] = d, b, c
+	m.link(b1, twCA)
+	m.link(b1+1, twAD)
+	m.link(b1+2, b2+2)
+	m.link(b2, twDB)
+	m.link(b2+1, twBC)
+	return []int{b1, b1 + 1, b2, b2 + 1}
+}
+
+// twice the signed area of triangle abc; positive when counterclockwise
+func orient2d(ax, ay, bx, by, cx, cy float64) float64 {
+	return (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+}
+
+// inCircle reports whether d is strictly inside the circumcircle of abc,
+// where orient is orient2d(a, b, c).  Points so close to the circle that
+// rounding could decide either way count as outside.
+func inCircle(ax, ay, bx, by, cx, cy, dx, dy, orient float64) bool {
+	adx, ady := ax-dx, ay-dy
+	bdx, bdy := bx-dx, by-dy
+	cdx, cdy := cx-dx, cy-dy
+	alift := adx*adx + ady*ady
+	blift := bdx*bdx + bdy*bdy
+	clift := cdx*cdx + cdy*cdy
+	det := alift*(bdx*cdy-cdx*bdy) - blift*(adx*cdy-cdx*ady) + clift*(adx*bdy-bdx*ady)
+	bound := alift*(math.Abs(bdx*cdy)+math.Abs(cdx*bdy)) +
+		blift*(math.Abs(adx*cdy)+math.Abs(cdx*ady)) +
+		clift*(math.Abs(adx*bdy)+math.Abs(bdx*ady))
+	if orient < 0 {
+		det = -det
+	}
+	return det > bound*1e-12
+}
@@ -0,0 +1,112 @@
+package earcut
+
+import (
+	"math"
+	"testing"
+)
+
+// minimum angle of a triangle, in degrees
+func minAngle(data []float64, dim int, a, b, c int) float64 {
+	ax, ay := data[a*dim], data[a*dim+1]
+	bx, by := data[b*dim], data[b*dim+1]
+	cx, cy := data[c*dim], data[c*dim+1]
+	angle := func(px, py, qx, qy, rx, ry float64) float64 {
+		ux, uy := qx-px, qy-py
+		vx, vy := rx-px, ry-py
+		return math.Abs(math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)) * 180 / math.Pi
+	}
+	return math.Min(angle(ax, ay, bx, by, cx, cy),
+		math.Min(angle(bx, by, cx, cy, ax, ay), angle(cx, cy, ax, ay, bx, by)))
+}
+
+func countSlivers(data []float64, dim int, tri []int) int {
+	n := 0
+	for i := 0; i < len(tri); i += 3 {
+		if minAngle(data, dim, tri[i], tri[i+1], tri[i+2]) < 5 {
+			n++
+		}
+	}
+	return n
+}
+
+func testDelaunay(name string, t *testing.T) {
+	flat, holeIndices, err := loadVertices(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := Earcut(flat, holeIndices, 2)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	tri, err := EarcutDelaunay(flat, holeIndices, 2)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	if len(tri) != len(orig) {
+		t.Errorf("Expected %d triangles, got %d for %s", len(orig)/3, len(tri)/3, name)
+	}
+	d0 := Deviation(flat, holeIndices, 2, orig)
+	if d := Deviation(flat, holeIndices, 2, tri); math.Abs(d-d0) > 1e-9 {
+		t.Errorf("Deviation changed from %g to %g for %s", d0, d, name)
+	}
+	if again := Delaunay(flat, holeIndices, 2, orig); !checkVerts(tri, again) {
+		t.Errorf("Delaunay isn't deterministic for %s", name)
+	}
+	if s0, s := countSlivers(flat, 2, orig), countSlivers(flat, 2, tri); s > s0 {
+		t.Errorf("Slivers went from %d to %d for %s", s0, s, name)
+	}
+
+	// ring edges survive
+	src0 := EdgeSources(flat, holeIndices, 2, orig)
+	src := EdgeSources(flat, holeIndices, 2, tri)
+	count := func(src []int) int {
+		n := 0
+		for _, s := range src {
+			if s >= 0 {
+				n++
+			}
+		}
+		return n
+	}
+	if count(src) != count(src0) {
+		t.Errorf("Expected %d ring edges, got %d for %s", count(src0), count(src), name)
+	}
+
+	// every internal edge is locally Delaunay
+	he := NewWeldedHalfEdges(flat, 2, tri)
+	for h, tw := range he.Twin {
+		if tw < 0 || src[h] >= 0 {
+			continue
+		}
+		a, b, c := tri[h], tri[he.Next(h)], tri[he.Prev(h)]
+		d := tri[he.Prev(tw)]
+		o := orient2d(flat[a*2], flat[a*2+1], flat[b*2], flat[b*2+1], flat[c*2], flat[c*2+1])
+		if o != 0 && inCircle(flat[a*2], flat[a*2+1], flat[b*2], flat[b*2+1], flat[c*2], flat[c*2+1], flat[d*2], flat[d*2+1], o) {
+			t.Errorf("Vertex %d is inside the circumcircle of triangle %d for %s", d, h/3, name)
+			return
+		}
+	}
+}
+
+func TestDelaunayParallelogram(t *testing.T) {
+	// a parallelogram leaning right, whose short diagonal is 1-3
+	data := []float64{0, 0, 10, 0, 10.5, 1, 0.5, 1}
+	tri := Delaunay(data, nil, 2, []int{0, 1, 3, 1, 2, 3})
+	if !checkVerts([]int{0, 1, 3, 1, 2, 3}, tri) {
+		t.Error("Triangle vertices don't match", tri)
+	}
+	tri = Delaunay(data, nil, 2, []int{0, 1, 2, 2, 3, 0})
+	if !checkVerts([]int{1, 2, 3, 3, 0, 1}, tri) {
+		t.Error("Triangle vertices don't match", tri)
+	}
+}
+
+func TestDelaunayFixtures(t *testing.T) {
+	for _, name := range []string{
+		"building", "dude", "water", "water2", "water3", "water3b", "water4",
+		"hilbert", "hole-touching-outer", "touching-holes", "steiner",
+		"issue16", "issue17", "shared-points", "bad-hole", "degenerate",
+	} {
+		testDelaunay(name, t)
+	}
+}
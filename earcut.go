@@ -31,7 +31,26 @@ type node struct {
 //
 // dim is the number of values per vertex.  Only the first two values (x & y)
 // will be considered when constructing the triangles.
+//
+// A hole with a single vertex is not cut out; the vertex is kept as a
+// Steiner point inside the polygon.  See EarcutSteiner.
 func Earcut(data []float64, holeIndices []int, dim int) ([]int, error) {
+	return EarcutT(data, holeIndices, dim)
+}
+
+// Number is the set of coordinate types accepted by EarcutT and DeviationT;
+// it is equivalent to constraints.Float | constraints.Integer.
+type Number interface {
+	~float32 | ~float64 |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// EarcutT is like Earcut, but accepts vertex data of any numeric type.  The
+// data is read in place; coordinates are converted to float64 as the polygon
+// nodes are built, so integer inputs are triangulated exactly as their
+// float64 equivalents would be.
+func EarcutT[T Number](data []T, holeIndices []int, dim int) ([]int, error) {
 	if dim < 2 {
 		return nil, errors.New("need at least 2 dimensions")
 	}
@@ -53,15 +72,15 @@ func Earcut(data []float64, holeIndices []int, dim int) ([]int, error) {
 	maxY := math.Inf(-1)
 	var x, y, invSize float64
 	if hasHoles {
-		outerNode = eliminateHoles(data, holeIndices, outerNode, dim)
+		outerNode = eliminateHoles(data, holeIndices, outerNode, dim, nil)
 	}
 
 	// if the shape is not too simple, we'll use z-order curve hash later;
 	// calculate polygon bbox
 	if len(data) > 80*dim {
 		for i := 0; i < outerLen; i += dim {
-			x = data[i]
-			y = data[i+1]
+			x = float64(data[i])
+			y = float64(data[i+1])
 			if x < minX {
 				minX = x
 			}
@@ -89,15 +108,15 @@ func Earcut(data []float64, holeIndices []int, dim int) ([]int, error) {
 
 // create a circular doubly linked list from polygon points in the specified
 // winding order
-func linkedList(data []float64, start, end, dim int, clockwise bool) *node {
+func linkedList[T Number](data []T, start, end, dim int, clockwise bool) *node {
 	var last *node
 	if clockwise == (signedArea(data, start, end, dim) > 0.0) {
 		for i := start; i < end; i += dim {
-			last = insertNode(i, data[i], data[i+1], last)
+			last = insertNode(i, float64(data[i]), float64(data[i+1]), last)
 		}
 	} else {
 		for i := end - dim; i >= start; i -= dim {
-			last = insertNode(i, data[i], data[i+1], last)
+			last = insertNode(i, float64(data[i]), float64(data[i+1]), last)
 		}
 	}
 	if last != nil && equals(last, last.next) {
@@ -358,7 +377,7 @@ func (q sortableQueue) Less(i, j int) bool { return q[i].x < q[j].x }
 
 // link every hole into the outer loop, producing a single-ring polygon
 // without holes
-func eliminateHoles(data []float64, holeIndices []int, outerNode *node, dim int) *node {
+func eliminateHoles[T Number](data []T, holeIndices []int, outerNode *node, dim int, bridges *[][2]int) *node {
 	queue := []*node{}
 	var start, end int
 	var list *node
@@ -371,17 +390,30 @@ func eliminateHoles(data []float64, holeIndices []int, outerNode *node, dim int)
 			end = len(data)
 		}
 		list = linkedList(data, start, end, dim, false)
+		if list == nil {
+			continue
+		}
 		if list == list.next {
 			list.steiner = true
 		}
 		queue = append(queue, getLeftmost(list))
 	}
 
+	return eliminateHoleQueue(queue, outerNode, bridges)
+}
+
+// link holes, given by their leftmost nodes, into the outer loop; if
+// bridges isn't nil, the data offsets of the ends of each bridge are
+// appended to it, outer end first
+func eliminateHoleQueue(queue []*node, outerNode *node, bridges *[][2]int) *node {
 	sort.Sort(sortableQueue(queue))
 
 	// process holes from left to right
 	for i := 0; i < len(queue); i++ {
-		eliminateHole(queue[i], outerNode)
+		bridge := eliminateHole(queue[i], outerNode)
+		if bridge != nil && bridges != nil {
+			*bridges = append(*bridges, [2]int{bridge.i, queue[i].i})
+		}
 		outerNode = filterPoints(outerNode, outerNode.next)
 	}
 
@@ -389,13 +421,35 @@ func eliminateHoles(data []float64, holeIndices []int, outerNode *node, dim int)
 }
 
 // find a bridge between vertices that connects hole with an outer ring and
-// link it
-func eliminateHole(hole, outerNode *node) {
+// link it; returns the outer end of the bridge, or nil if there's none
+func eliminateHole(hole, outerNode *node) *node {
 	outerNode = findHoleBridge(hole, outerNode)
 	if outerNode != nil {
 		b := splitPolygon(outerNode, hole)
 		filterPoints(b, b.next)
 	}
+	return outerNode
+}
+
+// holeBridges returns the bridges that Earcut makes to join each hole to
+// the outer ring, as pairs of vertex indices: the outer vertex, then the
+// hole vertex.  Later bridges can land on earlier holes, just as they do
+// when triangulating.
+func holeBridges(data []float64, holeIndices []int, dim int) [][2]int {
+	if len(holeIndices) == 0 {
+		return nil
+	}
+	outerNode := linkedList(data, 0, holeIndices[0]*dim, dim, true)
+	if outerNode == nil {
+		return nil
+	}
+	bridges := [][2]int{}
+	eliminateHoles(data, holeIndices, outerNode, dim, &bridges)
+	for i := range bridges {
+		bridges[i][0] /= dim
+		bridges[i][1] /= dim
+	}
+	return bridges
 }
 
 // David Eberly's algorithm for finding a bridge between hole and outer polygon
@@ -667,10 +721,14 @@ func locallyInside(a, b *node) bool {
 
 // check if the middle point of a polygon diagonal is inside the polygon
 func middleInside(a, b *node) bool {
-	p := a
+	return pointInRing(a, (a.x+b.x)/2.0, (a.y+b.y)/2.0)
+}
+
+// check if a point is inside the ring containing the start node, using the
+// even-odd rule
+func pointInRing(start *node, px, py float64) bool {
+	p := start
 	inside := false
-	px := (a.x + b.x) / 2.0
-	py := (a.y + b.y) / 2.0
 	for {
 		if ((p.y > py) != (p.next.y > py)) &&
 			p.next.y != p.y &&
@@ -678,7 +736,7 @@ func middleInside(a, b *node) bool {
 			inside = !inside
 		}
 		p = p.next
-		if p == a {
+		if p == start {
 			break
 		}
 	}
@@ -754,10 +812,11 @@ func newNode(i int, x, y float64) *node {
 	}
 }
 
-func signedArea(data []float64, start, end, dim int) float64 {
+func signedArea[T Number](data []T, start, end, dim int) float64 {
 	var sum float64
 	for i, j := start, end-dim; i < end; i += dim {
-		sum += (data[j] - data[i]) * (data[i+1] + data[j+1])
+		sum += (float64(data[j]) - float64(data[i])) *
+			(float64(data[i+1]) + float64(data[j+1]))
 		j = i
 	}
 	return sum
@@ -766,6 +825,11 @@ func signedArea(data []float64, start, end, dim int) float64 {
 // Deviation returns a percentage difference between the polygon area and
 // its triangulation area; used to verify correctness of triangulation
 func Deviation(data []float64, holeIndices []int, dim int, triangles []int) float64 {
+	return DeviationT(data, holeIndices, dim, triangles)
+}
+
+// DeviationT is like Deviation, but accepts vertex data of any numeric type.
+func DeviationT[T Number](data []T, holeIndices []int, dim int, triangles []int) float64 {
 	hasHoles := holeIndices != nil && len(holeIndices) > 0
 	var outerLen int
 	if hasHoles {
@@ -793,9 +857,10 @@ func Deviation(data []float64, holeIndices []int, dim int, triangles []int) floa
 		a := triangles[i] * dim
 		b := triangles[i+1] * dim
 		c := triangles[i+2] * dim
-		trianglesArea += math.Abs(
-			(data[a]-data[c])*(data[b+1]-data[a+1]) -
-				(data[a]-data[b])*(data[c+1]-data[a+1]))
+		ax, ay := float64(data[a]), float64(data[a+1])
+		bx, by := float64(data[b]), float64(data[b+1])
+		cx, cy := float64(data[c]), float64(data[c+1])
+		trianglesArea += math.Abs((ax-cx)*(by-ay) - (ax-bx)*(cy-ay))
 	}
 
 	if polygonArea == 0.0 && trianglesArea == 0.0 {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -120,6 +121,31 @@ func TestSimplePolyWithHole(t *testing.T) {
 	}
 }
 
+func TestEmptyHole(t *testing.T) {
+	path := []float64{
+		0.0, 0.0,
+		10.0, 0.0,
+		10.0, 10.0,
+		0.0, 10.0,
+		2.0, 2.0,
+		4.0, 2.0,
+		4.0, 4.0,
+	}
+	for _, holes := range [][]int{{4}, {4, 4}} {
+		tri, err := Earcut(path[:8], holes[:1], 2)
+		if err != nil || len(tri) != 6 {
+			t.Errorf("Expected 2 triangles with empty hole %v, got %v, %v", holes[:1], tri, err)
+		}
+		tri, err = Earcut(path, holes, 2)
+		if err != nil {
+			t.Errorf("Error making triangles with empty hole %v: %s", holes, err)
+		}
+		if d := Deviation(path, holes, 2, tri); d > epsilon {
+			t.Errorf("Triangle area not equal to polygon area with empty hole %v (%.6f%% deviation", holes, d*100.0)
+		}
+	}
+}
+
 func TestComplexPoly(t *testing.T) {
 	path := []float64{
 		0.0, 0.0, 1.0,
@@ -183,22 +209,6 @@ func TestComplexPoly(t *testing.T) {
 	}
 }
 
-func flatten(data [][][2]float64) ([]float64, []int) {
-	flat := []float64{}
-	holes := []int{}
-	j := 0
-	for i, ring := range data {
-		if i > 0 {
-			holes = append(holes, j)
-		}
-		for _, pt := range ring {
-			flat = append(flat, pt[0], pt[1])
-			j++
-		}
-	}
-	return flat, holes
-}
-
 func loadVertices(name string) ([]float64, []int, error) {
 	rawdata, err := ioutil.ReadFile(filepath.Join("fixtures", name+".json"))
 	if err != nil {
@@ -209,10 +219,31 @@ func loadVertices(name string) ([]float64, []int, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error unmarshaling json fixture data: %s", err)
 	}
-	flat, holeIndices := flatten(data)
+	flat, holeIndices := Flatten(data)
 	return flat, holeIndices, nil
 }
 
+// dumpSVG writes a debug image of a triangulation to the temp directory
+func dumpSVG(name string, data []float64, holeIndices []int, dim int, tri []int, t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "go-earcut")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Log("Error creating debug output directory:", err)
+		return
+	}
+	fn := filepath.Join(dir, name+".svg")
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Log("Error creating debug image:", err)
+		return
+	}
+	defer f.Close()
+	if err = WriteSVG(f, data, holeIndices, dim, tri, nil); err != nil {
+		t.Log("Error writing debug image:", err)
+		return
+	}
+	t.Log("Wrote debug image to", fn)
+}
+
 func testFixture(name string, expTriangles int, expDeviation float64, t *testing.T) {
 	flat, holeIndices, err := loadVertices(name)
 	if err != nil {
@@ -225,6 +256,7 @@ func testFixture(name string, expTriangles int, expDeviation float64, t *testing
 	d := Deviation(flat, holeIndices, 2, tri)
 	if d > expDeviation {
 		t.Errorf("Deviation %f greater than expected (%f) for %s", d, expDeviation, name)
+		dumpSVG(name, flat, holeIndices, 2, tri, t)
 	}
 	if len(tri)/3 != expTriangles {
 		t.Errorf("Expected %d triangles, got %d for fixture %s", expTriangles, len(tri)/3, name)
@@ -354,3 +386,62 @@ func TestFixtureBadDiagonals(t *testing.T) {
 func TestFixtureIssue83(t *testing.T) {
 	testFixture("issue83", 0, 1e-14, t)
 }
+
+func testFixtureT[T Number](name string, conv func(float64) T, t *testing.T) {
+	flat, holeIndices, err := loadVertices(name)
+	if err != nil {
+		t.Error(err)
+	}
+	data := make([]T, len(flat))
+	for i, v := range flat {
+		data[i] = conv(v)
+		flat[i] = float64(data[i])
+	}
+	exp, err := Earcut(flat, holeIndices, 2)
+	if err != nil {
+		t.Error("Error in earcut:", err)
+	}
+	tri, err := EarcutT(data, holeIndices, 2)
+	if err != nil {
+		t.Error("Error in earcut:", err)
+	}
+	if !checkVerts(exp, tri) {
+		t.Errorf("Triangle vertices don't match float64 result for %s", name)
+	}
+	if d, e := DeviationT(data, holeIndices, 2, tri), Deviation(flat, holeIndices, 2, exp); d != e {
+		t.Errorf("Deviation %f not equal to float64 deviation %f for %s", d, e, name)
+	}
+}
+
+func TestEarcutFloat32(t *testing.T) {
+	testFixtureT("hilbert", func(v float64) float32 { return float32(v) }, t)
+}
+
+func TestEarcutInt32(t *testing.T) {
+	testFixtureT("dude", func(v float64) int32 { return int32(v) }, t)
+}
+
+func TestEarcutInt64(t *testing.T) {
+	testFixtureT("building", func(v float64) int64 { return int64(v) }, t)
+}
+
+func TestEarcutUint16(t *testing.T) {
+	path := []uint16{
+		0, 0,
+		10, 0,
+		10, 10,
+		0, 10,
+	}
+	tri, err := EarcutT(path, nil, 2)
+	if err != nil {
+		t.Error("Error making triangles for a square:", err)
+	}
+	if len(tri) != 6 {
+		t.Errorf("Expected 6 vertex indices, got %d", len(tri))
+	}
+	if d := DeviationT(path, nil, 2, tri); d > epsilon {
+		t.Errorf(
+			"Triangle area not equal to polygon area (%.6f%% deviation",
+			d*100.0)
+	}
+}
@@ -0,0 +1,92 @@
+package earcut
+
+import (
+	"math"
+)
+
+// EdgeSources maps each triangle edge back to the input ring segment it
+// lies on.  Entry t*3+e describes the edge from vertex e to vertex e+1
+// (mod 3) of triangle t, as with HalfEdges.  The value is the index of the
+// input segment, or -1 for internal diagonals and the bridges that link
+// holes to the outer ring.
+//
+// Segment i is the segment of a ring that starts at vertex i and ends at
+// the next vertex of the same ring (wrapping around to the ring's first
+// vertex).  When a triangle edge covers several collinear segments, or part
+// of a longer one (where a hole touches the outer ring, for example), the
+// segment containing the edge's midpoint is reported.
+func EdgeSources(data []float64, holeIndices []int, dim int, triangles []int) []int {
+	he := NewWeldedHalfEdges(data, dim, triangles)
+	weld := weldVertices(data, dim)
+
+	// index every non-degenerate segment by its welded endpoints
+	type edge struct {
+		a, b int
+	}
+	segs := map[edge]int{}
+	segStarts := []int{}
+	next := make([]int, len(weld))
+	for i, l := 0, len(holeIndices); i <= l; i++ {
+		start, end := ringRange(data, holeIndices, dim, i)
+		for j, k := start/dim, end/dim-1; j < end/dim; j++ {
+			next[k] = j
+			a, b := weld[k], weld[j]
+			if a != b {
+				if _, ok := segs[edge{a, b}]; !ok {
+					segs[edge{a, b}] = k
+					segs[edge{b, a}] = k
+				}
+				segStarts = append(segStarts, k)
+			}
+			k = j
+		}
+	}
+
+	sources := make([]int, len(triangles))
+	for h := range triangles {
+		sources[h] = -1
+		a := weld[he.Origin(h)]
+		b := weld[he.Dest(h)]
+		if s, ok := segs[edge{a, b}]; ok {
+			sources[h] = s
+			continue
+		}
+		if he.Twin[h] >= 0 {
+			// shared with another triangle, so it's interior
+			continue
+		}
+		mx := (data[a*dim] + data[b*dim]) / 2
+		my := (data[a*dim+1] + data[b*dim+1]) / 2
+		for _, k := range segStarts {
+			p := k * dim
+			q := next[k] * dim
+			if onSegment(data[p], data[p+1], data[q], data[q+1], mx, my) {
+				sources[h] = k
+				break
+			}
+		}
+	}
+	return sources
+}
+
+// check whether a point lies on a segment, allowing for rounding in the
+// computation of the point
+func onSegment(ax, ay, bx, by, px, py float64) bool {
+	if px < math.Min(ax, bx) || px > math.Max(ax, bx) ||
+		py < math.Min(ay, by) || py > math.Max(ay, by) {
+		return false
+	}
+	cross := (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+	return math.Abs(cross) <= 1e-9*math.Hypot(bx-ax, by-ay)
+}
+
+// BoundaryEdges reports, for each triangle edge (indexed as for
+// EdgeSources), whether it lies on an input ring.
+func BoundaryEdges(data []float64, holeIndices []int, dim int, triangles []int) []bool {
+	sources := EdgeSources(data, holeIndices, dim, triangles)
+	flags := make([]bool, len(sources))
+	for h, s := range sources {
+		flags[h] = s >= 0
+	}
+	return flags
+}
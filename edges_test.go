@@ -0,0 +1,64 @@
+package earcut
+
+import (
+	"testing"
+)
+
+func TestEdgeSourcesBridge(t *testing.T) {
+	data, holes := squareWithHole().flatten()
+	tri, _ := Earcut(data, holes, 2)
+	sources := EdgeSources(data, holes, 2, tri)
+	seen := map[int]bool{}
+	for h, s := range sources {
+		if s < 0 {
+			continue
+		}
+		a, b := tri[h], tri[h-h%3+(h+1)%3]
+		// segment s joins vertex s to the next vertex of its ring; the
+		// closing points 4 and 9 duplicate 0 and 5
+		if s == 4 || s == 9 {
+			t.Errorf("Degenerate closing segment %d reported", s)
+		}
+		ends := map[int]bool{s: true, s + 1: true}
+		if s+1 == 4 {
+			ends[0] = true
+		}
+		if s+1 == 9 {
+			ends[5] = true
+		}
+		if !ends[a] || !ends[b] {
+			t.Errorf("Edge %d-%d doesn't match segment %d", a, b, s)
+		}
+		seen[s] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("Expected all 8 segments on boundary edges, got %d", len(seen))
+	}
+	flags := BoundaryEdges(data, holes, 2, tri)
+	n := 0
+	for _, f := range flags {
+		if f {
+			n++
+		}
+	}
+	if n != 8 {
+		t.Errorf("Expected 8 boundary edges, got %d", n)
+	}
+}
+
+func TestEdgeSourcesFixtures(t *testing.T) {
+	for _, name := range []string{"dude", "water3", "hole-touching-outer", "touching-holes"} {
+		flat, holeIndices, err := loadVertices(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tri, _ := Earcut(flat, holeIndices, 2)
+		he := NewWeldedHalfEdges(flat, 2, tri)
+		sources := EdgeSources(flat, holeIndices, 2, tri)
+		for h, s := range sources {
+			if he.Twin[h] < 0 && s < 0 {
+				t.Errorf("Boundary edge %d has no source segment for %s", h, name)
+			}
+		}
+	}
+}
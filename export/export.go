@@ -0,0 +1,47 @@
+// Package export writes earcut triangulations to common 3D mesh file
+// formats, for viewing in tools such as Blender or MeshLab.
+//
+// Every writer takes the flat vertex array and dim passed to earcut, along
+// with the triangle indices it returned.  The third value of each vertex,
+// if dim > 2, is written as z; otherwise z is 0.
+package export
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Format selects between the text and binary variants of a file format.
+type Format int
+
+// File format variants
+const (
+	ASCII Format = iota
+	Binary
+)
+
+// check that the triangulation is well formed
+func validate(data []float64, dim int, triangles []int) error {
+	if dim < 2 {
+		return errors.New("need at least 2 dimensions")
+	}
+	if len(triangles)%3 != 0 {
+		return fmt.Errorf("triangle index count %d is not a multiple of 3", len(triangles))
+	}
+	n := len(data) / dim
+	for _, v := range triangles {
+		if v < 0 || v >= n {
+			return fmt.Errorf("vertex index %d out of range", v)
+		}
+	}
+	return nil
+}
+
+// position of vertex i
+func vertex(data []float64, dim, i int) [3]float64 {
+	p := [3]float64{data[i*dim], data[i*dim+1], 0}
+	if dim > 2 {
+		p[2] = data[i*dim+2]
+	}
+	return p
+}
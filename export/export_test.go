@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// a unit square with a z value at each corner
+var (
+	square    = []float64{0, 0, 1, 1, 0, 2, 1, 1, 3, 0, 1, 4}
+	squareTri = []int{0, 1, 2, 2, 3, 0}
+)
+
+func loadFixture(name string, t *testing.T) ([]float64, []int) {
+	raw, err := ioutil.ReadFile(filepath.Join("..", "fixtures", name+".json"))
+	if err != nil {
+		t.Fatal("Error reading fixture data:", err)
+	}
+	rings := [][][2]float64{}
+	if err = json.Unmarshal(raw, &rings); err != nil {
+		t.Fatal("Error unmarshaling json fixture data:", err)
+	}
+	tri, data, err := earcut.EarcutRings(rings)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	return data, tri
+}
+
+func TestWriteOBJ(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteOBJ(buf, square, 3, squareTri); err != nil {
+		t.Fatal("Error writing OBJ:", err)
+	}
+	exp := "v 0 0 1\nv 1 0 2\nv 1 1 3\nv 0 1 4\nf 1 2 3\nf 3 4 1\n"
+	if buf.String() != exp {
+		t.Errorf("OBJ doesn't match:\n%s", buf.String())
+	}
+
+	data, tri := loadFixture("hilbert", t)
+	buf.Reset()
+	if err := WriteOBJ(buf, data, 2, tri); err != nil {
+		t.Fatal("Error writing OBJ:", err)
+	}
+	var nv, nf int
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		switch {
+		case strings.HasPrefix(scanner.Text(), "v "):
+			nv++
+		case strings.HasPrefix(scanner.Text(), "f "):
+			nf++
+		}
+	}
+	if nv != len(data)/2 || nf != len(tri)/3 {
+		t.Errorf("Expected %d vertices and %d faces, got %d and %d", len(data)/2, len(tri)/3, nv, nf)
+	}
+}
+
+func TestWritePLY(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WritePLY(buf, square, 3, squareTri, ASCII); err != nil {
+		t.Fatal("Error writing PLY:", err)
+	}
+	s := buf.String()
+	if !strings.HasPrefix(s, "ply\nformat ascii 1.0\nelement vertex 4\n") ||
+		!strings.Contains(s, "element face 2\n") ||
+		!strings.HasSuffix(s, "end_header\n0 0 1\n1 0 2\n1 1 3\n0 1 4\n3 0 1 2\n3 2 3 0\n") {
+		t.Errorf("ASCII PLY doesn't match:\n%s", s)
+	}
+
+	buf.Reset()
+	if err := WritePLY(buf, square, 3, squareTri, Binary); err != nil {
+		t.Fatal("Error writing PLY:", err)
+	}
+	b := buf.Bytes()
+	i := bytes.Index(b, []byte("end_header\n"))
+	if i < 0 || !bytes.Contains(b[:i], []byte("format binary_little_endian 1.0\n")) {
+		t.Fatal("Binary PLY header doesn't match")
+	}
+	body := b[i+len("end_header\n"):]
+	if len(body) != 4*3*8+2*13 {
+		t.Fatalf("Expected %d bytes of binary PLY data, got %d", 4*3*8+2*13, len(body))
+	}
+	if z := math.Float64frombits(binary.LittleEndian.Uint64(body[2*3*8+16:])); z != 3 {
+		t.Errorf("Expected z 3 for third vertex, got %f", z)
+	}
+	face := body[4*3*8+13:]
+	if face[0] != 3 || binary.LittleEndian.Uint32(face[1:]) != 2 || binary.LittleEndian.Uint32(face[9:]) != 0 {
+		t.Error("Binary PLY face doesn't match", face)
+	}
+}
+
+func TestWriteSTL(t *testing.T) {
+	buf := &bytes.Buffer{}
+	flat := []float64{0, 0, 1, 0, 1, 1, 0, 1}
+	if err := WriteSTL(buf, flat, 2, squareTri, "square", ASCII); err != nil {
+		t.Fatal("Error writing STL:", err)
+	}
+	s := buf.String()
+	if !strings.HasPrefix(s, "solid square\n  facet normal 0 0 1\n    outer loop\n      vertex 0 0 0\n") ||
+		!strings.HasSuffix(s, "endsolid square\n") ||
+		strings.Count(s, "facet normal") != 2 {
+		t.Errorf("ASCII STL doesn't match:\n%s", s)
+	}
+
+	data, tri := loadFixture("eberly-6", t)
+	buf.Reset()
+	if err := WriteSTL(buf, data, 2, tri, "eberly-6", Binary); err != nil {
+		t.Fatal("Error writing STL:", err)
+	}
+	b := buf.Bytes()
+	n := len(tri) / 3
+	if len(b) != 84+n*50 || binary.LittleEndian.Uint32(b[80:]) != uint32(n) {
+		t.Fatalf("Binary STL size doesn't match for %d triangles", n)
+	}
+	for i := 0; i < n; i++ {
+		// earcut triangles all wind the same way, so the normals agree
+		nz := math.Float32frombits(binary.LittleEndian.Uint32(b[84+i*50+8:]))
+		if nz != 1 && nz != 0 {
+			t.Fatalf("Expected normal z of 1 for triangle %d, got %f", i, nz)
+		}
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteOBJ(buf, square, 1, squareTri); err == nil {
+		t.Error("Expected error for 1 dimension")
+	}
+	if err := WritePLY(buf, square, 3, []int{0, 1}, ASCII); err == nil {
+		t.Error("Expected error for partial triangle")
+	}
+	if err := WriteSTL(buf, square, 3, []int{0, 1, 4}, "x", Binary); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+}
@@ -0,0 +1,342 @@
+package export
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// Primitive is a single triangulated polygon to be written as a glTF mesh
+// primitive.  Vertices may be shared by several primitives (as with the
+// output of earcut.EarcutMulti); only the vertices referenced by Indices
+// are written.
+type Primitive struct {
+	Vertices []float64
+	Dim      int
+	Indices  []int
+}
+
+// glTF constants
+const (
+	glbMagic       = 0x46546C67
+	glbVersion     = 2
+	chunkJSON      = 0x4E4F534A
+	chunkBIN       = 0x004E4942
+	targetArray    = 34962
+	targetElements = 34963
+	typeUByte      = 5121
+	typeUShort     = 5123
+	typeUInt       = 5125
+	typeFloat      = 5126
+	modeTriangles  = 4
+)
+
+type gltfDoc struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       *int             `json:"scene,omitempty"`
+	Scenes      []gltfScene      `json:"scenes,omitempty"`
+	Nodes       []gltfNode       `json:"nodes,omitempty"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Mesh int `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride,omitempty"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ByteOffset    int       `json:"byteOffset,omitempty"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+// WriteGLB writes triangulated polygons as a binary glTF 2.0 file, with one
+// mesh holding one primitive per polygon.  Positions are written as 32-bit
+// floats, with z taken from the third value of each vertex when Dim > 2,
+// and indices use the smallest component type that fits without using its
+// largest value, which glTF reserves for primitive restart.  Primitives with
+// no triangles are skipped, since glTF doesn't allow empty accessors; if
+// none are left, the file has no mesh, buffer or binary chunk at all.
+func WriteGLB(w io.Writer, prims []Primitive) error {
+	doc := &gltfDoc{
+		Asset: gltfAsset{Version: "2.0", Generator: "earcut"},
+	}
+	mesh := gltfMesh{Primitives: []gltfPrimitive{}}
+	bin := []byte{}
+	view := func(b []byte, target int) int {
+		doc.BufferViews = append(doc.BufferViews, gltfBufferView{
+			ByteOffset: len(bin),
+			ByteLength: len(b),
+			Target:     target,
+		})
+		bin = append(bin, b...)
+		for len(bin)%4 != 0 {
+			bin = append(bin, 0)
+		}
+		return len(doc.BufferViews) - 1
+	}
+
+	for i, prim := range prims {
+		if err := validate(prim.Vertices, prim.Dim, prim.Indices); err != nil {
+			return fmt.Errorf("primitive %d: %s", i, err)
+		}
+		if len(prim.Indices) == 0 {
+			continue
+		}
+
+		// compact the vertices used by this primitive
+		local := map[int]int{}
+		order := []int{}
+		indices := make([]int, len(prim.Indices))
+		for j, v := range prim.Indices {
+			k, ok := local[v]
+			if !ok {
+				k = len(order)
+				local[v] = k
+				order = append(order, v)
+			}
+			indices[j] = k
+		}
+
+		pos := make([]byte, 0, len(order)*12)
+		min := []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+		max := []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+		for _, v := range order {
+			p := vertex(prim.Vertices, prim.Dim, v)
+			for k, c := range p {
+				f := float32(c)
+				if f < min[k] {
+					min[k] = f
+				}
+				if f > max[k] {
+					max[k] = f
+				}
+				pos = binary.LittleEndian.AppendUint32(pos, math.Float32bits(f))
+			}
+		}
+		doc.Accessors = append(doc.Accessors, gltfAccessor{
+			BufferView:    view(pos, targetArray),
+			ComponentType: typeFloat,
+			Count:         len(order),
+			Type:          "VEC3",
+			Min:           min,
+			Max:           max,
+		})
+
+		var idx []byte
+		var ctype int
+		switch {
+		case len(order) <= math.MaxUint8:
+			ctype = typeUByte
+			for _, k := range indices {
+				idx = append(idx, uint8(k))
+			}
+		case len(order) <= math.MaxUint16:
+			ctype = typeUShort
+			for _, k := range indices {
+				idx = binary.LittleEndian.AppendUint16(idx, uint16(k))
+			}
+		default:
+			ctype = typeUInt
+			for _, k := range indices {
+				idx = binary.LittleEndian.AppendUint32(idx, uint32(k))
+			}
+		}
+		doc.Accessors = append(doc.Accessors, gltfAccessor{
+			BufferView:    view(idx, targetElements),
+			ComponentType: ctype,
+			Count:         len(indices),
+			Type:          "SCALAR",
+		})
+		indexAccessor := len(doc.Accessors) - 1
+		mesh.Primitives = append(mesh.Primitives, gltfPrimitive{
+			Attributes: map[string]int{"POSITION": len(doc.Accessors) - 2},
+			Indices:    &indexAccessor,
+			Mode:       modeTriangles,
+		})
+	}
+	// a mesh needs at least one primitive, and a buffer at least one byte
+	if len(mesh.Primitives) > 0 {
+		scene := 0
+		doc.Scene = &scene
+		doc.Scenes = []gltfScene{{Nodes: []int{0}}}
+		doc.Nodes = []gltfNode{{Mesh: 0}}
+		doc.Meshes = []gltfMesh{mesh}
+		doc.Buffers = []gltfBuffer{{ByteLength: len(bin)}}
+	}
+
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	total := 12 + 8 + len(js)
+	if len(bin) > 0 {
+		total += 8 + len(bin)
+	}
+	out := make([]byte, 0, total)
+	out = binary.LittleEndian.AppendUint32(out, glbMagic)
+	out = binary.LittleEndian.AppendUint32(out, glbVersion)
+	out = binary.LittleEndian.AppendUint32(out, uint32(total))
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(js)))
+	out = binary.LittleEndian.AppendUint32(out, chunkJSON)
+	out = append(out, js...)
+	if len(bin) > 0 {
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(bin)))
+		out = binary.LittleEndian.AppendUint32(out, chunkBIN)
+		out = append(out, bin...)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// ReadGLB reads back the primitives of the first mesh of a binary glTF
+// file, such as one written by WriteGLB.  Only triangle primitives with
+// float VEC3 positions and indices stored in the file's own buffer, packed
+// without gaps between elements, are supported.  Vertices are returned
+// with 3 values per vertex.
+func ReadGLB(r io.Reader) ([]Primitive, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 20 || binary.LittleEndian.Uint32(b) != glbMagic {
+		return nil, errors.New("glb: not a binary glTF file")
+	}
+	if v := binary.LittleEndian.Uint32(b[4:]); v != glbVersion {
+		return nil, fmt.Errorf("glb: unsupported version %d", v)
+	}
+	var js, bin []byte
+	for pos := 12; pos+8 <= len(b); {
+		l := int(binary.LittleEndian.Uint32(b[pos:]))
+		typ := binary.LittleEndian.Uint32(b[pos+4:])
+		pos += 8
+		if l < 0 || pos+l > len(b) {
+			return nil, errors.New("glb: truncated chunk")
+		}
+		switch typ {
+		case chunkJSON:
+			js = b[pos : pos+l]
+		case chunkBIN:
+			bin = b[pos : pos+l]
+		}
+		pos += l
+	}
+	doc := &gltfDoc{}
+	if err = json.Unmarshal(js, doc); err != nil {
+		return nil, fmt.Errorf("glb: %s", err)
+	}
+	if len(doc.Meshes) == 0 {
+		return []Primitive{}, nil
+	}
+	accessor := func(i int, size int) ([]byte, *gltfAccessor, error) {
+		if i < 0 || i >= len(doc.Accessors) {
+			return nil, nil, fmt.Errorf("glb: accessor %d out of range", i)
+		}
+		a := &doc.Accessors[i]
+		if a.BufferView < 0 || a.BufferView >= len(doc.BufferViews) {
+			return nil, nil, fmt.Errorf("glb: buffer view %d out of range", a.BufferView)
+		}
+		v := doc.BufferViews[a.BufferView]
+		if v.ByteStride != 0 && v.ByteStride != size {
+			return nil, nil, fmt.Errorf("glb: unsupported byte stride %d", v.ByteStride)
+		}
+		if v.ByteOffset < 0 || a.ByteOffset < 0 || a.Count < 0 ||
+			v.ByteOffset+v.ByteLength > len(bin) || a.ByteOffset+a.Count*size > v.ByteLength {
+			return nil, nil, errors.New("glb: accessor out of buffer bounds")
+		}
+		start := v.ByteOffset + a.ByteOffset
+		return bin[start : start+a.Count*size], a, nil
+	}
+	prims := []Primitive{}
+	for _, p := range doc.Meshes[0].Primitives {
+		if p.Mode != modeTriangles {
+			return nil, fmt.Errorf("glb: unsupported primitive mode %d", p.Mode)
+		}
+		posIdx, ok := p.Attributes["POSITION"]
+		if !ok {
+			return nil, errors.New("glb: primitive has no positions")
+		}
+		pos, a, err := accessor(posIdx, 12)
+		if err != nil {
+			return nil, err
+		}
+		if a.ComponentType != typeFloat || a.Type != "VEC3" {
+			return nil, errors.New("glb: unsupported position accessor")
+		}
+		prim := Primitive{Vertices: make([]float64, a.Count*3), Dim: 3}
+		for i := range prim.Vertices {
+			prim.Vertices[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(pos[i*4:])))
+		}
+		if p.Indices == nil {
+			return nil, errors.New("glb: primitive has no indices")
+		}
+		if len(doc.Accessors) <= *p.Indices || *p.Indices < 0 {
+			return nil, fmt.Errorf("glb: accessor %d out of range", *p.Indices)
+		}
+		size := map[int]int{typeUByte: 1, typeUShort: 2, typeUInt: 4}[doc.Accessors[*p.Indices].ComponentType]
+		if size == 0 {
+			return nil, errors.New("glb: unsupported index component type")
+		}
+		idx, a, err := accessor(*p.Indices, size)
+		if err != nil {
+			return nil, err
+		}
+		prim.Indices = make([]int, a.Count)
+		for i := range prim.Indices {
+			switch size {
+			case 1:
+				prim.Indices[i] = int(idx[i])
+			case 2:
+				prim.Indices[i] = int(binary.LittleEndian.Uint16(idx[i*2:]))
+			case 4:
+				prim.Indices[i] = int(binary.LittleEndian.Uint32(idx[i*4:]))
+			}
+		}
+		prims = append(prims, prim)
+	}
+	return prims, nil
+}
@@ -0,0 +1,258 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// read the JSON chunk of a GLB file
+func glbJSON(b []byte, t *testing.T) *gltfDoc {
+	if binary.LittleEndian.Uint32(b) != glbMagic || int(binary.LittleEndian.Uint32(b[8:])) != len(b) {
+		t.Fatal("GLB header doesn't match")
+	}
+	l := binary.LittleEndian.Uint32(b[12:])
+	if l%4 != 0 || binary.LittleEndian.Uint32(b[16:]) != chunkJSON {
+		t.Fatal("GLB JSON chunk header doesn't match")
+	}
+	doc := &gltfDoc{}
+	if err := json.Unmarshal(b[20:20+l], doc); err != nil {
+		t.Fatal("Error parsing GLB JSON:", err)
+	}
+	return doc
+}
+
+func TestGLBRoundTrip(t *testing.T) {
+	building, buildingTri := loadFixture("building", t)
+	water, waterTri := loadFixture("water", t)
+
+	// a 3D multipolygon sharing one vertex array
+	data := []float64{
+		0, 0, 1, 4, 0, 2, 4, 4, 3, 0, 4, 4,
+		10, 0, 5, 12, 0, 6, 12, 2, 7,
+	}
+	multiTri, ranges, err := earcut.EarcutMulti(data, []earcut.Part{{Start: 0, End: 4}, {Start: 4, End: 7}}, 3)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	prims := []Primitive{
+		{Vertices: building, Dim: 2, Indices: buildingTri},
+		{Vertices: water, Dim: 2, Indices: waterTri},
+		{Vertices: data, Dim: 3, Indices: multiTri[ranges[0].Start:ranges[0].End]},
+		{Vertices: data, Dim: 3, Indices: []int{}},
+		{Vertices: data, Dim: 3, Indices: multiTri[ranges[1].Start:ranges[1].End]},
+	}
+	buf := &bytes.Buffer{}
+	if err = WriteGLB(buf, prims); err != nil {
+		t.Fatal("Error writing GLB:", err)
+	}
+	doc := glbJSON(buf.Bytes(), t)
+	if n := len(doc.Meshes[0].Primitives); n != 4 {
+		t.Fatalf("Expected 4 primitives, got %d", n)
+	}
+	expTypes := []int{typeUByte, typeUShort, typeUByte, typeUByte}
+	for i, p := range doc.Meshes[0].Primitives {
+		if ct := doc.Accessors[*p.Indices].ComponentType; ct != expTypes[i] {
+			t.Errorf("Expected index type %d for primitive %d, got %d", expTypes[i], i, ct)
+		}
+	}
+	pos := doc.Accessors[doc.Meshes[0].Primitives[3].Attributes["POSITION"]]
+	if pos.Count != 3 || pos.Min[0] != 10 || pos.Max[1] != 2 || pos.Min[2] != 5 || pos.Max[2] != 7 {
+		t.Errorf("Position accessor doesn't match: %+v", pos)
+	}
+
+	got, err := ReadGLB(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal("Error reading GLB:", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 primitives, got %d", len(got))
+	}
+	for i, src := range []Primitive{prims[0], prims[1], prims[2], prims[4]} {
+		g := got[i]
+		if len(g.Indices) != len(src.Indices) {
+			t.Errorf("Primitive %d has %d indices, expected %d", i, len(g.Indices), len(src.Indices))
+			continue
+		}
+		for j, v := range src.Indices {
+			exp := vertex(src.Vertices, src.Dim, v)
+			for k := 0; k < 3; k++ {
+				if float32(exp[k]) != float32(g.Vertices[g.Indices[j]*3+k]) {
+					t.Fatalf("Primitive %d vertex %d doesn't match", i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestGLBLargeIndices(t *testing.T) {
+	n := 70000
+	data := make([]float64, n*2)
+	for i := 0; i < n; i++ {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		data[i*2] = 1000 * math.Cos(a)
+		data[i*2+1] = 1000 * math.Sin(a)
+	}
+	// a triangle fan is enough here, and much faster than earcut
+	tri := make([]int, 0, (n-2)*3)
+	for i := 1; i < n-1; i++ {
+		tri = append(tri, 0, i, i+1)
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteGLB(buf, []Primitive{{Vertices: data, Dim: 2, Indices: tri}}); err != nil {
+		t.Fatal("Error writing GLB:", err)
+	}
+	doc := glbJSON(buf.Bytes(), t)
+	if ct := doc.Accessors[1].ComponentType; ct != typeUInt {
+		t.Errorf("Expected 32-bit indices, got type %d", ct)
+	}
+	got, err := ReadGLB(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal("Error reading GLB:", err)
+	}
+	if len(got) != 1 || len(got[0].Indices) != len(tri) {
+		t.Error("Round trip doesn't match")
+	}
+}
+
+func TestReadGLBErrors(t *testing.T) {
+	if _, err := ReadGLB(bytes.NewReader([]byte("not a glb file at all"))); err == nil {
+		t.Error("Expected error for bad magic")
+	}
+	buf := &bytes.Buffer{}
+	WriteGLB(buf, []Primitive{{Vertices: square, Dim: 3, Indices: squareTri}})
+	b := buf.Bytes()
+	if _, err := ReadGLB(bytes.NewReader(b[:len(b)-4])); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+}
+
+func TestGLBEmpty(t *testing.T) {
+	for _, prims := range [][]Primitive{nil, {{Vertices: square, Dim: 3, Indices: []int{}}}} {
+		buf := &bytes.Buffer{}
+		if err := WriteGLB(buf, prims); err != nil {
+			t.Fatal("Error writing GLB:", err)
+		}
+		b := buf.Bytes()
+		doc := glbJSON(b, t)
+		if doc.Meshes != nil || doc.Buffers != nil || doc.BufferViews != nil || doc.Accessors != nil {
+			t.Errorf("Expected no mesh or buffer, got %+v", doc)
+		}
+		if l := 20 + int(binary.LittleEndian.Uint32(b[12:])); l != len(b) {
+			t.Errorf("Expected no binary chunk, got %d more bytes", len(b)-l)
+		}
+		got, err := ReadGLB(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal("Error reading GLB:", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("Expected no primitives, got %d", len(got))
+		}
+	}
+}
+
+// build a GLB file from a document and binary chunk
+func makeGLB(doc *gltfDoc, bin []byte) []byte {
+	js, _ := json.Marshal(doc)
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	out := binary.LittleEndian.AppendUint32(nil, glbMagic)
+	out = binary.LittleEndian.AppendUint32(out, glbVersion)
+	out = binary.LittleEndian.AppendUint32(out, uint32(28+len(js)+len(bin)))
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(js)))
+	out = binary.LittleEndian.AppendUint32(out, chunkJSON)
+	out = append(out, js...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(bin)))
+	out = binary.LittleEndian.AppendUint32(out, chunkBIN)
+	return append(out, bin...)
+}
+
+func TestReadGLBAccessorOffset(t *testing.T) {
+	// positions and indices packed into one view, as some exporters do
+	bin := []byte{}
+	for _, c := range []float32{0, 0, 0, 1, 0, 0, 0, 1, 0} {
+		bin = binary.LittleEndian.AppendUint32(bin, math.Float32bits(c))
+	}
+	bin = binary.LittleEndian.AppendUint16(bin, 2)
+	bin = binary.LittleEndian.AppendUint16(bin, 0)
+	bin = binary.LittleEndian.AppendUint16(bin, 1)
+	bin = append(bin, 0, 0)
+	one := 1
+	doc := &gltfDoc{
+		Asset:       gltfAsset{Version: "2.0"},
+		Meshes:      []gltfMesh{{Primitives: []gltfPrimitive{{Attributes: map[string]int{"POSITION": 0}, Indices: &one, Mode: modeTriangles}}}},
+		Buffers:     []gltfBuffer{{ByteLength: len(bin)}},
+		BufferViews: []gltfBufferView{{ByteLength: len(bin)}},
+		Accessors: []gltfAccessor{
+			{ComponentType: typeFloat, Count: 3, Type: "VEC3"},
+			{ByteOffset: 36, ComponentType: typeUShort, Count: 3, Type: "SCALAR"},
+		},
+	}
+	got, err := ReadGLB(bytes.NewReader(makeGLB(doc, bin)))
+	if err != nil {
+		t.Fatal("Error reading GLB:", err)
+	}
+	if len(got) != 1 || len(got[0].Indices) != 3 || got[0].Indices[0] != 2 || got[0].Indices[2] != 1 {
+		t.Errorf("Indices don't match: %+v", got)
+	}
+
+	doc.Accessors[1].ByteOffset = 40
+	if _, err = ReadGLB(bytes.NewReader(makeGLB(doc, bin))); err == nil {
+		t.Error("Expected error for accessor past the end of its view")
+	}
+	doc.Accessors[1].ByteOffset = 36
+	doc.BufferViews[0].ByteStride = 16
+	if _, err = ReadGLB(bytes.NewReader(makeGLB(doc, bin))); err == nil {
+		t.Error("Expected error for interleaved view")
+	}
+	doc.BufferViews[0].ByteStride = 0
+	doc.Meshes[0].Primitives[0].Indices = nil
+	if _, err = ReadGLB(bytes.NewReader(makeGLB(doc, bin))); err == nil {
+		t.Error("Expected error for primitive without indices")
+	}
+}
+
+func TestGLBIndexTypes(t *testing.T) {
+	// the largest value of each type is the primitive restart index, so
+	// it can't be used as a vertex index
+	for _, tc := range []struct {
+		n     int
+		ctype int
+	}{
+		{255, typeUByte},
+		{256, typeUShort},
+		{65535, typeUShort},
+		{65536, typeUInt},
+	} {
+		data := make([]float64, tc.n*2)
+		for i := 0; i < tc.n; i++ {
+			a := 2 * math.Pi * float64(i) / float64(tc.n)
+			data[i*2] = math.Cos(a)
+			data[i*2+1] = math.Sin(a)
+		}
+		tri := make([]int, 0, (tc.n-2)*3)
+		for i := 1; i < tc.n-1; i++ {
+			tri = append(tri, 0, i, i+1)
+		}
+		buf := &bytes.Buffer{}
+		if err := WriteGLB(buf, []Primitive{{Vertices: data, Dim: 2, Indices: tri}}); err != nil {
+			t.Fatal("Error writing GLB:", err)
+		}
+		doc := glbJSON(buf.Bytes(), t)
+		if ct := doc.Accessors[1].ComponentType; ct != tc.ctype {
+			t.Errorf("Expected index type %d for %d vertices, got %d", tc.ctype, tc.n, ct)
+		}
+		got, err := ReadGLB(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal("Error reading GLB:", err)
+		}
+		if len(got) != 1 || len(got[0].Indices) != len(tri) || got[0].Indices[len(tri)-1] != tc.n-1 {
+			t.Errorf("Round trip doesn't match for %d vertices", tc.n)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// WriteOBJ writes a triangulation as a Wavefront OBJ file.  Every vertex is
+// written, used or not, so that face indices match the source data.
+func WriteOBJ(w io.Writer, data []float64, dim int, triangles []int) error {
+	if err := validate(data, dim, triangles); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	buf := []byte{}
+	for i := 0; i < len(data)/dim; i++ {
+		p := vertex(data, dim, i)
+		buf = append(buf[:0], 'v')
+		for _, v := range p {
+			buf = append(buf, ' ')
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+		}
+		buf = append(buf, '\n')
+		bw.Write(buf)
+	}
+	for t := 0; t < len(triangles); t += 3 {
+		buf = append(buf[:0], 'f')
+		for _, v := range triangles[t : t+3] {
+			buf = append(buf, ' ')
+			buf = strconv.AppendInt(buf, int64(v+1), 10)
+		}
+		buf = append(buf, '\n')
+		bw.Write(buf)
+	}
+	return bw.Flush()
+}
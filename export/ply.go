@@ -0,0 +1,69 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// WritePLY writes a triangulation as a Stanford PLY file, in either the
+// ASCII or the binary little endian encoding.  Coordinates are written as
+// doubles.  Every vertex is written, used or not, so that face indices
+// match the source data.
+func WritePLY(w io.Writer, data []float64, dim int, triangles []int, format Format) error {
+	if err := validate(data, dim, triangles); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	encoding := "ascii"
+	if format == Binary {
+		encoding = "binary_little_endian"
+	}
+	n := len(data) / dim
+	fmt.Fprintf(bw, "ply\nformat %s 1.0\n", encoding)
+	fmt.Fprintf(bw, "element vertex %d\n", n)
+	bw.WriteString("property double x\nproperty double y\nproperty double z\n")
+	fmt.Fprintf(bw, "element face %d\n", len(triangles)/3)
+	bw.WriteString("property list uchar int vertex_indices\nend_header\n")
+
+	buf := []byte{}
+	for i := 0; i < n; i++ {
+		p := vertex(data, dim, i)
+		buf = buf[:0]
+		for j, v := range p {
+			if format == Binary {
+				buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+				continue
+			}
+			if j > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+		}
+		if format != Binary {
+			buf = append(buf, '\n')
+		}
+		bw.Write(buf)
+	}
+	for t := 0; t < len(triangles); t += 3 {
+		buf = buf[:0]
+		if format == Binary {
+			buf = append(buf, 3)
+			for _, v := range triangles[t : t+3] {
+				buf = binary.LittleEndian.AppendUint32(buf, uint32(v))
+			}
+		} else {
+			buf = append(buf, '3')
+			for _, v := range triangles[t : t+3] {
+				buf = append(buf, ' ')
+				buf = strconv.AppendInt(buf, int64(v), 10)
+			}
+			buf = append(buf, '\n')
+		}
+		bw.Write(buf)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// facet normal of a triangle, or the zero vector for degenerate triangles
+func normal(a, b, c [3]float64) [3]float64 {
+	u := [3]float64{b[0] - a[0], b[1] - a[1], b[2] - a[2]}
+	v := [3]float64{c[0] - a[0], c[1] - a[1], c[2] - a[2]}
+	n := [3]float64{
+		u[1]*v[2] - u[2]*v[1],
+		u[2]*v[0] - u[0]*v[2],
+		u[0]*v[1] - u[1]*v[0],
+	}
+	l := math.Sqrt(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])
+	if l == 0 {
+		return [3]float64{}
+	}
+	return [3]float64{n[0] / l, n[1] / l, n[2] / l}
+}
+
+// WriteSTL writes a triangulation as an STL file, in either the ASCII or
+// the binary encoding.  name is used for the ASCII solid name, and is
+// ignored for binary files.  Binary STL stores coordinates as 32-bit
+// floats.
+func WriteSTL(w io.Writer, data []float64, dim int, triangles []int, name string, format Format) error {
+	if err := validate(data, dim, triangles); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	if format == Binary {
+		var header [84]byte
+		copy(header[:80], "binary STL written by earcut")
+		binary.LittleEndian.PutUint32(header[80:], uint32(len(triangles)/3))
+		bw.Write(header[:])
+	} else {
+		fmt.Fprintf(bw, "solid %s\n", name)
+	}
+	buf := make([]byte, 0, 50)
+	for t := 0; t < len(triangles); t += 3 {
+		a := vertex(data, dim, triangles[t])
+		b := vertex(data, dim, triangles[t+1])
+		c := vertex(data, dim, triangles[t+2])
+		n := normal(a, b, c)
+		if format == Binary {
+			buf = buf[:0]
+			for _, p := range [4][3]float64{n, a, b, c} {
+				for _, v := range p {
+					buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v)))
+				}
+			}
+			buf = append(buf, 0, 0)
+			bw.Write(buf)
+			continue
+		}
+		fmt.Fprintf(bw, "  facet normal %g %g %g\n", n[0], n[1], n[2])
+		bw.WriteString("    outer loop\n")
+		for _, p := range [3][3]float64{a, b, c} {
+			fmt.Fprintf(bw, "      vertex %g %g %g\n", p[0], p[1], p[2])
+		}
+		bw.WriteString("    endloop\n  endfacet\n")
+	}
+	if format != Binary {
+		fmt.Fprintf(bw, "endsolid %s\n", name)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,217 @@
+// Package geojson decodes GeoJSON polygons and triangulates them with
+// earcut.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// Geometry is a GeoJSON geometry object.  Only Polygon and MultiPolygon
+// geometries can be triangulated.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Feature is a GeoJSON Feature object.
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection object.
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+// Mesh is the triangulation of a single GeoJSON geometry.
+type Mesh struct {
+	// ID and Properties are copied from the enclosing feature, if any.
+	ID         interface{}
+	Properties map[string]interface{}
+
+	// Vertices is the flat vertex array of every ring of every polygon,
+	// with Dim values per vertex.  Dim is 2 for planar coordinates and
+	// 3 or more when positions carry altitude or other extra values.
+	Vertices []float64
+	Dim      int
+
+	// Indices holds 3 vertex indices per triangle.
+	Indices []int
+
+	// Polygons holds the range of Indices belonging to each polygon of
+	// the geometry; a Polygon geometry has exactly one.
+	Polygons []earcut.IndexRange
+}
+
+// Decode reads a GeoJSON Polygon, MultiPolygon, Feature or
+// FeatureCollection from r and triangulates every polygonal geometry in it.
+// Features without a polygonal geometry are skipped.
+func Decode(r io.Reader) ([]*Mesh, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	return Unmarshal(raw)
+}
+
+// Unmarshal is like Decode, but reads the GeoJSON from a byte slice.
+func Unmarshal(data []byte) ([]*Mesh, error) {
+	var obj struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	switch obj.Type {
+	case "Polygon", "MultiPolygon":
+		g := &Geometry{}
+		if err := json.Unmarshal(data, g); err != nil {
+			return nil, err
+		}
+		mesh, err := g.Triangulate()
+		if err != nil {
+			return nil, err
+		}
+		return []*Mesh{mesh}, nil
+	case "Feature":
+		f := &Feature{}
+		if err := json.Unmarshal(data, f); err != nil {
+			return nil, err
+		}
+		if !f.polygonal() {
+			return []*Mesh{}, nil
+		}
+		mesh, err := f.Triangulate()
+		if err != nil {
+			return nil, err
+		}
+		return []*Mesh{mesh}, nil
+	case "FeatureCollection":
+		fc := &FeatureCollection{}
+		if err := json.Unmarshal(data, fc); err != nil {
+			return nil, err
+		}
+		return fc.Triangulate()
+	}
+	return nil, fmt.Errorf("unsupported GeoJSON type %q", obj.Type)
+}
+
+// Triangulate triangulates every polygonal feature in the collection.
+// Features without a polygonal geometry are skipped.
+func (fc *FeatureCollection) Triangulate() ([]*Mesh, error) {
+	meshes := []*Mesh{}
+	for i, f := range fc.Features {
+		if f == nil || !f.polygonal() {
+			continue
+		}
+		mesh, err := f.Triangulate()
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %s", i, err)
+		}
+		meshes = append(meshes, mesh)
+	}
+	return meshes, nil
+}
+
+func (f *Feature) polygonal() bool {
+	return f.Geometry != nil &&
+		(f.Geometry.Type == "Polygon" || f.Geometry.Type == "MultiPolygon")
+}
+
+// Triangulate triangulates the feature's geometry, and attaches the
+// feature's ID and properties to the resulting mesh.
+func (f *Feature) Triangulate() (*Mesh, error) {
+	if f.Geometry == nil {
+		return nil, fmt.Errorf("feature has no geometry")
+	}
+	mesh, err := f.Geometry.Triangulate()
+	if err != nil {
+		return nil, err
+	}
+	mesh.ID = f.ID
+	mesh.Properties = f.Properties
+	return mesh, nil
+}
+
+// Triangulate triangulates a Polygon or MultiPolygon geometry.
+func (g *Geometry) Triangulate() (*Mesh, error) {
+	var polygons [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var poly [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &poly); err != nil {
+			return nil, err
+		}
+		polygons = [][][][]float64{poly}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("can't triangulate %s geometry", g.Type)
+	}
+	return triangulate(polygons)
+}
+
+// flatten the polygons into a single vertex array, using enough values per
+// vertex to hold the longest position; shorter positions are padded with
+// zeros
+func flatten(polygons [][][][]float64) ([]float64, []earcut.Part, int, error) {
+	dim := 2
+	n := 0
+	for _, poly := range polygons {
+		for _, ring := range poly {
+			for _, pos := range ring {
+				if len(pos) < 2 {
+					return nil, nil, 0, fmt.Errorf("position has %d values, need at least 2", len(pos))
+				}
+				if len(pos) > dim {
+					dim = len(pos)
+				}
+				n++
+			}
+		}
+	}
+	data := make([]float64, n*dim)
+	parts := make([]earcut.Part, len(polygons))
+	j := 0
+	for i, poly := range polygons {
+		parts[i].Start = j
+		for k, ring := range poly {
+			if k > 0 && len(ring) > 0 {
+				parts[i].Holes = append(parts[i].Holes, j)
+			}
+			for _, pos := range ring {
+				copy(data[j*dim:], pos)
+				j++
+			}
+		}
+		parts[i].End = j
+	}
+	return data, parts, dim, nil
+}
+
+func triangulate(polygons [][][][]float64) (*Mesh, error) {
+	data, parts, dim, err := flatten(polygons)
+	if err != nil {
+		return nil, err
+	}
+	indices, ranges, err := earcut.EarcutMulti(data, parts, dim)
+	if err != nil {
+		return nil, err
+	}
+	return &Mesh{
+		Vertices: data,
+		Dim:      dim,
+		Indices:  indices,
+		Polygons: ranges,
+	}, nil
+}
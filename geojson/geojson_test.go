@@ -0,0 +1,162 @@
+package geojson
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+var epsilon = float64(1.0e-12)
+
+func TestDecodePolygonFixture(t *testing.T) {
+	coords, err := ioutil.ReadFile(filepath.Join("..", "fixtures", "water3.json"))
+	if err != nil {
+		t.Fatal("Error reading fixture data:", err)
+	}
+	src := fmt.Sprintf(`{"type": "Polygon", "coordinates": %s}`, coords)
+	meshes, err := Decode(strings.NewReader(src))
+	if err != nil {
+		t.Fatal("Error decoding polygon:", err)
+	}
+	if len(meshes) != 1 {
+		t.Fatalf("Expected 1 mesh, got %d", len(meshes))
+	}
+	mesh := meshes[0]
+	if mesh.Dim != 2 {
+		t.Errorf("Expected dim 2, got %d", mesh.Dim)
+	}
+	if len(mesh.Indices)/3 != 197 {
+		t.Errorf("Expected 197 triangles, got %d", len(mesh.Indices)/3)
+	}
+	if len(mesh.Polygons) != 1 || mesh.Polygons[0].End != len(mesh.Indices) {
+		t.Error("Polygon ranges don't match", mesh.Polygons)
+	}
+}
+
+func TestDecodeFeature3D(t *testing.T) {
+	src := `{
+		"type": "Feature",
+		"id": "f1",
+		"properties": {"name": "squares"},
+		"geometry": {
+			"type": "MultiPolygon",
+			"coordinates": [
+				[
+					[[0, 0, 1], [4, 0, 1], [4, 4, 1], [0, 4, 1], [0, 0, 1]],
+					[[1, 1, 2], [1, 2, 2], [2, 2, 2], [2, 1, 2], [1, 1, 2]]
+				],
+				[
+					[[10, 0], [12, 0], [12, 2], [10, 2], [10, 0]]
+				]
+			]
+		}
+	}`
+	meshes, err := Unmarshal([]byte(src))
+	if err != nil {
+		t.Fatal("Error decoding feature:", err)
+	}
+	if len(meshes) != 1 {
+		t.Fatalf("Expected 1 mesh, got %d", len(meshes))
+	}
+	mesh := meshes[0]
+	if mesh.ID != "f1" {
+		t.Errorf("Expected id f1, got %v", mesh.ID)
+	}
+	if mesh.Properties["name"] != "squares" {
+		t.Errorf("Expected name property, got %v", mesh.Properties)
+	}
+	if mesh.Dim != 3 {
+		t.Errorf("Expected dim 3, got %d", mesh.Dim)
+	}
+	if len(mesh.Vertices) != 15*3 {
+		t.Errorf("Expected %d values, got %d", 15*3, len(mesh.Vertices))
+	}
+	if mesh.Vertices[5*3+2] != 2 || mesh.Vertices[10*3+2] != 0 {
+		t.Error("Extra dimensions not mapped", mesh.Vertices)
+	}
+	if len(mesh.Polygons) != 2 {
+		t.Fatalf("Expected 2 polygons, got %d", len(mesh.Polygons))
+	}
+	if n := mesh.Polygons[0].End - mesh.Polygons[0].Start; n != 24 {
+		t.Errorf("Expected 24 indices for first polygon, got %d", n)
+	}
+	if n := mesh.Polygons[1].End - mesh.Polygons[1].Start; n != 6 {
+		t.Errorf("Expected 6 indices for second polygon, got %d", n)
+	}
+	for _, i := range mesh.Indices[mesh.Polygons[1].Start:] {
+		if i < 10 {
+			t.Errorf("Index %d not in second polygon", i)
+		}
+	}
+	r := mesh.Polygons[0]
+	d := earcut.Deviation(mesh.Vertices[:10*3], []int{5}, 3, mesh.Indices[r.Start:r.End])
+	if d > epsilon {
+		t.Errorf("Triangle area not equal to polygon area (%.6f%% deviation", d*100.0)
+	}
+}
+
+func TestDecodeFeatureCollection(t *testing.T) {
+	src := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"id": 1,
+				"properties": null,
+				"geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 1]]]}
+			},
+			{
+				"type": "Feature",
+				"properties": {},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"id": 3,
+				"properties": {},
+				"geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}
+			}
+		]
+	}`
+	meshes, err := Unmarshal([]byte(src))
+	if err != nil {
+		t.Fatal("Error decoding feature collection:", err)
+	}
+	if len(meshes) != 2 {
+		t.Fatalf("Expected 2 meshes, got %d", len(meshes))
+	}
+	if meshes[0].ID != float64(1) || meshes[1].ID != float64(3) {
+		t.Errorf("Feature ids don't match: %v, %v", meshes[0].ID, meshes[1].ID)
+	}
+	if len(meshes[0].Indices) != 3 || len(meshes[1].Indices) != 6 {
+		t.Error("Triangle counts don't match")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	bad := []string{
+		`{"type": "Point", "coordinates": [0, 0]}`,
+		`{"type": "Polygon", "coordinates": [[[0], [1, 0], [0, 1]]]}`,
+		`{"type": "Polygon", "coordinates": "nope"}`,
+		`not json`,
+	}
+	for _, src := range bad {
+		if _, err := Unmarshal([]byte(src)); err == nil {
+			t.Errorf("Expected error decoding %s", src)
+		}
+	}
+
+	// holes with nothing around them
+	hole := `[[1, 1], [2, 1], [2, 2], [1, 1]]`
+	for _, outer := range []string{`[]`, `[[0, 0], [4, 0], [0, 0]]`} {
+		src := `{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [0, 1]]], [` + outer + `, ` + hole + `]]}`
+		_, err := Unmarshal([]byte(src))
+		if err == nil || !strings.HasPrefix(err.Error(), "part 1: ") {
+			t.Errorf("Expected error naming part 1 for %s, got %v", outer, err)
+		}
+	}
+}
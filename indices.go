@@ -0,0 +1,119 @@
+package earcut
+
+import (
+	"fmt"
+	"math"
+)
+
+// Uint32Indices converts a triangle index list, as returned by Earcut, into
+// a 32-bit index buffer.
+func Uint32Indices(triangles []int) ([]uint32, error) {
+	out := make([]uint32, len(triangles))
+	for i, v := range triangles {
+		if v < 0 || uint64(v) > math.MaxUint32 {
+			return nil, fmt.Errorf("vertex index %d doesn't fit in 32 bits", v)
+		}
+		out[i] = uint32(v)
+	}
+	return out, nil
+}
+
+// Uint16Indices converts a triangle index list into a 16-bit index buffer.
+// If any index is too large for 16 bits, use Uint16Chunks instead.
+func Uint16Indices(triangles []int) ([]uint16, error) {
+	out := make([]uint16, len(triangles))
+	for i, v := range triangles {
+		if v < 0 || v > math.MaxUint16 {
+			return nil, fmt.Errorf("vertex index %d doesn't fit in 16 bits", v)
+		}
+		out[i] = uint16(v)
+	}
+	return out, nil
+}
+
+// Chunk is a part of a triangulation with its own vertex buffer, small
+// enough to be drawn with 16-bit indices.
+type Chunk struct {
+	// Vertices holds the vertices used by the chunk, with the same
+	// number of values per vertex as the source data.
+	Vertices []float64
+
+	// Indices holds 3 indices into Vertices per triangle.
+	Indices []uint16
+
+	// Source maps each vertex of the chunk back to its index in the
+	// source data.  It is nil when Vertices is the source data itself.
+	Source []int
+}
+
+// Uint16Chunks converts a triangulation into 16-bit index buffers.  Index
+// 0xFFFF is never used, since graphics APIs reserve it for primitive
+// restart.  If every index is below it, a single chunk using the source
+// data is returned.  Otherwise the triangles are split, in order, into
+// chunks of at most 65,535 vertices, each with its own remapped vertex
+// buffer.
+func Uint16Chunks(data []float64, dim int, triangles []int) ([]Chunk, error) {
+	return chunk(data, dim, triangles, math.MaxUint16)
+}
+
+func chunk(data []float64, dim int, triangles []int, maxVerts int) ([]Chunk, error) {
+	if dim < 1 {
+		return nil, fmt.Errorf("invalid dimension %d", dim)
+	}
+	if len(data)%dim != 0 {
+		return nil, fmt.Errorf("vertex data length %d is not a multiple of %d", len(data), dim)
+	}
+	if len(triangles)%3 != 0 {
+		return nil, fmt.Errorf("triangle index count %d is not a multiple of 3", len(triangles))
+	}
+	n := len(data) / dim
+	fits := true
+	for _, v := range triangles {
+		if v < 0 || v >= n {
+			return nil, fmt.Errorf("vertex index %d out of range", v)
+		}
+		if v >= maxVerts {
+			fits = false
+		}
+	}
+	if fits {
+		indices := make([]uint16, len(triangles))
+		for i, v := range triangles {
+			indices[i] = uint16(v)
+		}
+		return []Chunk{{Vertices: data, Indices: indices}}, nil
+	}
+
+	chunks := []Chunk{}
+	var cur *Chunk
+	local := map[int]uint16{}
+	for t := 0; t < len(triangles); t += 3 {
+		tri := triangles[t : t+3]
+		added := 0
+		for _, v := range tri {
+			if _, ok := local[v]; !ok {
+				added++
+			}
+		}
+		if cur == nil || len(cur.Source)+added > maxVerts {
+			chunks = append(chunks, Chunk{
+				Vertices: []float64{},
+				Indices:  []uint16{},
+				Source:   []int{},
+			})
+			cur = &chunks[len(chunks)-1]
+			local = map[int]uint16{}
+		}
+		for _, v := range tri {
+			i, ok := local[v]
+			if !ok {
+				i = uint16(len(cur.Source))
+				local[v] = i
+				cur.Source = append(cur.Source, v)
+				cur.Vertices = append(cur.Vertices, data[v*dim:(v+1)*dim]...)
+			}
+			cur.Indices = append(cur.Indices, i)
+		}
+	}
+	return chunks, nil
+}
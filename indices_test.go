@@ -0,0 +1,135 @@
+package earcut
+
+import (
+	"testing"
+)
+
+func TestUint32Indices(t *testing.T) {
+	out, err := Uint32Indices([]int{0, 1, 70000})
+	if err != nil {
+		t.Fatal("Error converting indices:", err)
+	}
+	if out[2] != 70000 {
+		t.Error("Indices don't match", out)
+	}
+	if _, err = Uint32Indices([]int{-1}); err == nil {
+		t.Error("Expected error for negative index")
+	}
+}
+
+func TestUint16Indices(t *testing.T) {
+	out, err := Uint16Indices([]int{0, 1, 65535})
+	if err != nil {
+		t.Fatal("Error converting indices:", err)
+	}
+	if out[2] != 65535 {
+		t.Error("Indices don't match", out)
+	}
+	if _, err = Uint16Indices([]int{65536}); err == nil {
+		t.Error("Expected error for index too large for 16 bits")
+	}
+}
+
+// check that chunks reproduce the source triangles exactly
+func checkChunks(data []float64, dim int, tri []int, chunks []Chunk, maxVerts int, t *testing.T) {
+	i := 0
+	for c, ch := range chunks {
+		nv := len(ch.Vertices) / dim
+		if nv > maxVerts {
+			t.Errorf("Chunk %d has %d vertices", c, nv)
+		}
+		for _, v := range ch.Indices {
+			src := int(v)
+			if ch.Source != nil {
+				src = ch.Source[v]
+			}
+			if src != tri[i] {
+				t.Fatalf("Chunk %d index %d maps to %d, expected %d", c, v, src, tri[i])
+			}
+			for k := 0; k < dim; k++ {
+				if ch.Vertices[int(v)*dim+k] != data[src*dim+k] {
+					t.Fatalf("Chunk %d vertex %d doesn't match source", c, v)
+				}
+			}
+			i++
+		}
+	}
+	if i != len(tri) {
+		t.Errorf("Chunks hold %d indices, expected %d", i, len(tri))
+	}
+}
+
+func TestUint16ChunksSmall(t *testing.T) {
+	flat, holeIndices, err := loadVertices("dude")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tri, _ := Earcut(flat, holeIndices, 2)
+	chunks, err := Uint16Chunks(flat, 2, tri)
+	if err != nil {
+		t.Fatal("Error chunking:", err)
+	}
+	if len(chunks) != 1 || chunks[0].Source != nil {
+		t.Fatalf("Expected a single chunk using the source data, got %d", len(chunks))
+	}
+	checkChunks(flat, 2, tri, chunks, 65536, t)
+}
+
+func TestUint16ChunksSplit(t *testing.T) {
+	flat, holeIndices, err := loadVertices("water-huge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tri, _ := Earcut(flat, holeIndices, 2)
+	for _, max := range []int{3, 100, 1000} {
+		chunks, err := chunk(flat, 2, tri, max)
+		if err != nil {
+			t.Fatal("Error chunking:", err)
+		}
+		if len(chunks) < 2 {
+			t.Errorf("Expected multiple chunks with %d vertices each", max)
+		}
+		checkChunks(flat, 2, tri, chunks, max, t)
+	}
+}
+
+func TestUint16ChunksErrors(t *testing.T) {
+	data := []float64{0, 0, 1, 0, 0, 1}
+	if _, err := Uint16Chunks(data, 2, []int{0, 1}); err == nil {
+		t.Error("Expected error for partial triangle")
+	}
+	if _, err := Uint16Chunks(data, 2, []int{0, 1, 3}); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+	if _, err := Uint16Chunks(data, 0, []int{0, 1, 2}); err == nil {
+		t.Error("Expected error for zero dimension")
+	}
+	if _, err := Uint16Chunks(data[:5], 2, []int{0, 1, 2}); err == nil {
+		t.Error("Expected error for partial vertex")
+	}
+}
+
+func TestUint16ChunksLimit(t *testing.T) {
+	for _, n := range []int{65535, 65536} {
+		data := make([]float64, n*2)
+		for i := range data {
+			data[i] = float64(i)
+		}
+		// a fan using every vertex, up to index n-1
+		tri := make([]int, 0, (n-2)*3)
+		for i := 1; i < n-1; i++ {
+			tri = append(tri, 0, i, i+1)
+		}
+		chunks, err := Uint16Chunks(data, 2, tri)
+		if err != nil {
+			t.Fatal("Error chunking:", err)
+		}
+		if n == 65535 && (len(chunks) != 1 || chunks[0].Source != nil) {
+			t.Errorf("Expected a single chunk using the source data for %d vertices, got %d", n, len(chunks))
+		}
+		if n == 65536 && len(chunks) != 2 {
+			t.Errorf("Expected 2 chunks for %d vertices, got %d", n, len(chunks))
+		}
+		checkChunks(data, 2, tri, chunks, 65535, t)
+	}
+}
@@ -0,0 +1,159 @@
+package earcut
+
+import (
+	"math"
+)
+
+// Mesh is a triangulation together with the data it was built from, and
+// the ring each triangle vertex came from.
+type Mesh struct {
+	// Vertices is the flat vertex array, with Dim values per vertex.
+	Vertices []float64
+	Dim      int
+
+	// Indices holds 3 vertex indices per triangle.
+	Indices []int
+
+	// Parts is the polygon each vertex belongs to, and Rings is the
+	// ring within that polygon: 0 for the outer ring, and i for hole
+	// i-1.  Both are indexed by vertex.
+	Parts []int
+	Rings []int
+
+	// TriangleParts is the polygon each triangle belongs to.
+	TriangleParts []int
+
+	// Bounds is the bounding box of all of the vertices.
+	Bounds Rect
+
+	// Area is the area of the input polygons (outer rings less holes),
+	// and TriangleArea is the total area of the triangles.  They differ
+	// when the input can't be triangulated exactly; see Deviation.
+	Area         float64
+	TriangleArea float64
+}
+
+// NewMesh triangulates a polygon and returns the result as a Mesh.  The
+// arguments are as for Earcut, except that hole indices must be strictly
+// increasing.  Empty holes at the end of holeIndices are ignored.
+func NewMesh(data []float64, holeIndices []int, dim int) (*Mesh, error) {
+	var n int
+	if dim > 0 {
+		n = len(data) / dim
+	}
+	for len(holeIndices) > 0 && holeIndices[len(holeIndices)-1] == n {
+		holeIndices = holeIndices[:len(holeIndices)-1]
+	}
+	return NewMultiMesh(data, []Part{{Start: 0, End: n, Holes: holeIndices}}, dim)
+}
+
+// NewMultiMesh triangulates several polygons sharing a vertex array and
+// returns the result as a Mesh.  The arguments are as for EarcutMulti.
+func NewMultiMesh(data []float64, parts []Part, dim int) (*Mesh, error) {
+	indices, ranges, err := EarcutMulti(data, parts, dim)
+	if err != nil {
+		return nil, err
+	}
+	n := len(data) / dim
+	m := &Mesh{
+		Vertices:      data,
+		Dim:           dim,
+		Indices:       indices,
+		Parts:         make([]int, n),
+		Rings:         make([]int, n),
+		TriangleParts: make([]int, len(indices)/3),
+		Bounds: Rect{
+			Min: Point{math.Inf(1), math.Inf(1)},
+			Max: Point{math.Inf(-1), math.Inf(-1)},
+		},
+	}
+	for i := range m.Parts {
+		m.Parts[i] = -1
+		m.Rings[i] = -1
+	}
+	for p, part := range parts {
+		ring := 0
+		for v := part.Start; v < part.End; v++ {
+			for ring < len(part.Holes) && v >= part.Holes[ring] {
+				ring++
+			}
+			m.Parts[v] = p
+			m.Rings[v] = ring
+		}
+		holeIndices := make([]int, len(part.Holes))
+		for j, h := range part.Holes {
+			holeIndices[j] = h - part.Start
+		}
+		m.Area += polygonArea(data[part.Start*dim:part.End*dim], holeIndices, dim)
+		for t := ranges[p].Start / 3; t < ranges[p].End/3; t++ {
+			m.TriangleParts[t] = p
+		}
+	}
+	for i := 0; i+1 < len(data); i += dim {
+		m.Bounds.Min.X = math.Min(m.Bounds.Min.X, data[i])
+		m.Bounds.Min.Y = math.Min(m.Bounds.Min.Y, data[i+1])
+		m.Bounds.Max.X = math.Max(m.Bounds.Max.X, data[i])
+		m.Bounds.Max.Y = math.Max(m.Bounds.Max.Y, data[i+1])
+	}
+	for t := 0; t < m.NumTriangles(); t++ {
+		m.TriangleArea += m.triangleArea(t)
+	}
+	return m, nil
+}
+
+// NumTriangles returns the number of triangles in the mesh.
+func (m *Mesh) NumTriangles() int {
+	return len(m.Indices) / 3
+}
+
+// Triangle returns the vertex indices of triangle t.
+func (m *Mesh) Triangle(t int) [3]int {
+	return [3]int{m.Indices[t*3], m.Indices[t*3+1], m.Indices[t*3+2]}
+}
+
+// TriangleRings returns the ring of each vertex of triangle t.  A triangle
+// may touch several rings when it spans the bridge between an outer ring
+// and a hole.
+func (m *Mesh) TriangleRings(t int) [3]int {
+	tri := m.Triangle(t)
+	return [3]int{m.Rings[tri[0]], m.Rings[tri[1]], m.Rings[tri[2]]}
+}
+
+// TrianglesTouching returns the triangles with at least one vertex on the
+// given ring of the given polygon.
+func (m *Mesh) TrianglesTouching(part, ring int) []int {
+	tris := []int{}
+	for t := 0; t < m.NumTriangles(); t++ {
+		if m.TriangleParts[t] != part {
+			continue
+		}
+		for _, r := range m.TriangleRings(t) {
+			if r == ring {
+				tris = append(tris, t)
+				break
+			}
+		}
+	}
+	return tris
+}
+
+// Deviation returns the relative difference between the polygon area and
+// the triangle area, as Deviation does.
+func (m *Mesh) Deviation() float64 {
+	if m.Area == 0.0 && m.TriangleArea == 0.0 {
+		return 0.0
+	}
+	if m.Area == 0.0 {
+		return math.Inf(1)
+	}
+	return math.Abs((m.TriangleArea - m.Area) / m.Area)
+}
+
+func (m *Mesh) triangleArea(t int) float64 {
+	tri := m.Triangle(t)
+	a := tri[0] * m.Dim
+	b := tri[1] * m.Dim
+	c := tri[2] * m.Dim
+	d := m.Vertices
+	return math.Abs((d[a]-d[c])*(d[b+1]-d[a+1])-(d[a]-d[b])*(d[c+1]-d[a+1])) / 2.0
+}
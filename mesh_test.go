@@ -0,0 +1,99 @@
+package earcut
+
+import (
+	"testing"
+)
+
+func TestMesh(t *testing.T) {
+	poly := squareWithHole()
+	data, holes := poly.flatten()
+	m, err := NewMesh(data, holes, 2)
+	if err != nil {
+		t.Fatal("Error making mesh:", err)
+	}
+	if m.NumTriangles() != 8 {
+		t.Errorf("Expected 8 triangles, got %d", m.NumTriangles())
+	}
+	if m.Area != 15.0 || m.TriangleArea != 15.0 || m.Deviation() != 0.0 {
+		t.Errorf("Areas don't match: %f, %f", m.Area, m.TriangleArea)
+	}
+	exp := Rect{Min: Point{0, 0}, Max: Point{4, 4}}
+	if m.Bounds != exp {
+		t.Errorf("Expected bounds %v, got %v", exp, m.Bounds)
+	}
+	if !checkVerts([]int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1}, m.Rings) {
+		t.Error("Vertex rings don't match", m.Rings)
+	}
+	touching := m.TrianglesTouching(0, 1)
+	if len(touching) == 0 {
+		t.Error("Expected triangles touching the hole")
+	}
+	for _, tri := range touching {
+		r := m.TriangleRings(tri)
+		if r[0] != 1 && r[1] != 1 && r[2] != 1 {
+			t.Errorf("Triangle %d doesn't touch the hole: %v", tri, r)
+		}
+	}
+}
+
+func TestMeshEmptyHoles(t *testing.T) {
+	data := []float64{0, 0, 1, 0, 1, 1}
+	for _, holes := range [][]int{{3}, {3, 3}} {
+		m, err := NewMesh(data, holes, 2)
+		if err != nil {
+			t.Errorf("Error making mesh with empty holes %v: %s", holes, err)
+			continue
+		}
+		if m.NumTriangles() != 1 {
+			t.Errorf("Expected 1 triangle with empty holes %v, got %d", holes, m.NumTriangles())
+		}
+	}
+}
+
+func TestMultiMesh(t *testing.T) {
+	data := []float64{
+		0, 0, 4, 0, 4, 4, 0, 4,
+		1, 1, 1, 2, 2, 2, 2, 1,
+		10, 0, 12, 0, 12, 2,
+	}
+	parts := []Part{
+		{Start: 0, End: 8, Holes: []int{4}},
+		{Start: 8, End: 11},
+	}
+	m, err := NewMultiMesh(data, parts, 2)
+	if err != nil {
+		t.Fatal("Error making mesh:", err)
+	}
+	if !checkVerts([]int{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1}, m.Parts) {
+		t.Error("Vertex parts don't match", m.Parts)
+	}
+	if !checkVerts([]int{0, 0, 0, 0, 0, 0, 0, 0, 1}, m.TriangleParts) {
+		t.Error("Triangle parts don't match", m.TriangleParts)
+	}
+	if m.Area != 17.0 || m.Deviation() > epsilon {
+		t.Errorf("Expected area 17, got %f", m.Area)
+	}
+	if m.Bounds.Max.X != 12 {
+		t.Errorf("Bounds don't match: %v", m.Bounds)
+	}
+	if len(m.TrianglesTouching(1, 0)) != 1 {
+		t.Error("Expected 1 triangle in second polygon")
+	}
+}
+
+func TestMeshFixtures(t *testing.T) {
+	for _, name := range []string{"water", "bad-hole", "hilbert"} {
+		flat, holeIndices, err := loadVertices(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := NewMesh(flat, holeIndices, 2)
+		if err != nil {
+			t.Fatal("Error making mesh:", err)
+		}
+		d := Deviation(flat, holeIndices, 2, m.Indices)
+		if diff := m.Deviation() - d; diff > epsilon || diff < -epsilon {
+			t.Errorf("Mesh deviation %g doesn't match %g for %s", m.Deviation(), d, name)
+		}
+	}
+}
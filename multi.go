@@ -0,0 +1,118 @@
+package earcut
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Part describes a single polygon within a vertex array shared by several
+// polygons.  Start and End are vertex indices (not data indices) of the
+// first vertex of the polygon and one past its last vertex.  Holes are the
+// vertex indices of the start of each hole, relative to the start of the
+// shared array; they must be increasing and lie strictly between Start and
+// End.  A part with holes must have at least 3 distinct exterior vertices,
+// not counting a closing vertex, since otherwise there's nothing for the
+// holes to be cut from.
+type Part struct {
+	Start int
+	End   int
+	Holes []int
+}
+
+// IndexRange is a half-open range of positions in an index buffer.
+type IndexRange struct {
+	Start int
+	End   int
+}
+
+// Geometry is a set of polygons sharing one flat vertex array, as decoded
+// from formats such as WKB, WKT and shapefiles that carry optional Z and M
+// ordinates.
+type Geometry struct {
+	// HasZ and HasM report which extra ordinates each vertex carries.
+	HasZ bool
+	HasM bool
+
+	// Dim is the number of values per vertex: 2, plus one each for Z
+	// and M.  Values are stored in x, y, z, m order.
+	Dim int
+
+	// Data is the flat vertex array of every ring of every polygon.
+	Data []float64
+
+	// Polygons locates each polygon and its holes within Data.
+	Polygons []Part
+}
+
+// Polygon returns the vertex data and hole indices of polygon i, ready to
+// pass to Earcut along with g.Dim.
+func (g *Geometry) Polygon(i int) ([]float64, []int) {
+	part := g.Polygons[i]
+	holeIndices := make([]int, len(part.Holes))
+	for j, h := range part.Holes {
+		holeIndices[j] = h - part.Start
+	}
+	return g.Data[part.Start*g.Dim : part.End*g.Dim], holeIndices
+}
+
+// Triangulate triangulates every polygon in the geometry.  Indices refer
+// to vertices in g.Data; the range of indices belonging to each polygon is
+// returned alongside.
+func (g *Geometry) Triangulate() ([]int, []IndexRange, error) {
+	return EarcutMulti(g.Data, g.Polygons, g.Dim)
+}
+
+// EarcutMulti triangulates several polygons that share a single flat vertex
+// array, such as the parts of a MultiPolygon.  It returns one combined index
+// buffer referencing the shared array, along with the range of that buffer
+// holding the triangles of each part.
+func EarcutMulti(data []float64, parts []Part, dim int) ([]int, []IndexRange, error) {
+	if dim < 2 {
+		return nil, nil, errors.New("need at least 2 dimensions")
+	}
+	n := len(data) / dim
+	triangles := []int{}
+	ranges := make([]IndexRange, len(parts))
+	for i, part := range parts {
+		if part.Start < 0 || part.End > n || part.Start > part.End {
+			return nil, nil, fmt.Errorf("part %d: vertex range [%d, %d) out of bounds", i, part.Start, part.End)
+		}
+		holeIndices := make([]int, len(part.Holes))
+		for j, h := range part.Holes {
+			if h < part.Start || h >= part.End || (j > 0 && h <= part.Holes[j-1]) {
+				return nil, nil, fmt.Errorf("part %d: invalid hole index %d", i, h)
+			}
+			holeIndices[j] = h - part.Start
+		}
+		if len(holeIndices) > 0 && openRingLength(data, part.Start, part.Holes[0], dim) < 3 {
+			return nil, nil, fmt.Errorf("part %d: exterior ring has %d vertices but the part has holes", i, holeIndices[0])
+		}
+		tri, err := Earcut(data[part.Start*dim:part.End*dim], holeIndices, dim)
+		if err != nil {
+			return nil, nil, err
+		}
+		ranges[i].Start = len(triangles)
+		for _, v := range tri {
+			triangles = append(triangles, v+part.Start)
+		}
+		ranges[i].End = len(triangles)
+	}
+	return triangles, ranges, nil
+}
+
+// openRingLength returns the number of vertices in the ring [start, end),
+// not counting a closing vertex equal to the first
+func openRingLength(data []float64, start, end, dim int) int {
+	n := end - start
+	if n < 2 {
+		return n
+	}
+	a := data[start*dim : start*dim+dim]
+	b := data[(end-1)*dim : end*dim]
+	for k := range a {
+		if a[k] != b[k] {
+			return n
+		}
+	}
+	return n - 1
+}
@@ -0,0 +1,77 @@
+package earcut
+
+import (
+	"testing"
+)
+
+func TestEarcutMulti(t *testing.T) {
+	a, aHoles, err := loadVertices("building")
+	if err != nil {
+		t.Error(err)
+	}
+	b, bHoles, err := loadVertices("issue16")
+	if err != nil {
+		t.Error(err)
+	}
+	c, cHoles, err := loadVertices("steiner")
+	if err != nil {
+		t.Error(err)
+	}
+	polys := []struct {
+		data  []float64
+		holes []int
+	}{{a, aHoles}, {b, bHoles}, {c, cHoles}}
+	data := []float64{}
+	parts := []Part{}
+	for _, poly := range polys {
+		start := len(data) / 2
+		part := Part{Start: start, End: start + len(poly.data)/2}
+		for _, h := range poly.holes {
+			part.Holes = append(part.Holes, h+start)
+		}
+		parts = append(parts, part)
+		data = append(data, poly.data...)
+	}
+	tri, ranges, err := EarcutMulti(data, parts, 2)
+	if err != nil {
+		t.Error("Error in EarcutMulti:", err)
+	}
+	if len(ranges) != 3 {
+		t.Errorf("Expected 3 ranges, got %d", len(ranges))
+	}
+	if len(tri)/3 != 13+12+9 {
+		t.Errorf("Expected %d triangles, got %d", 13+12+9, len(tri)/3)
+	}
+	for i, part := range parts {
+		r := ranges[i]
+		exp, _ := Earcut(polys[i].data, polys[i].holes, 2)
+		if r.End-r.Start != len(exp) {
+			t.Errorf("Expected %d indices for part %d, got %d", len(exp), i, r.End-r.Start)
+			continue
+		}
+		for j, v := range tri[r.Start:r.End] {
+			if v < part.Start || v >= part.End || v-part.Start != exp[j] {
+				t.Errorf("Index %d out of place for part %d", v, i)
+				break
+			}
+		}
+	}
+}
+
+func TestEarcutMultiInvalid(t *testing.T) {
+	data := []float64{0, 0, 1, 0, 1, 1, 0, 1}
+	bad := [][]Part{
+		{{Start: 0, End: 5}},
+		{{Start: 3, End: 1}},
+		{{Start: 0, End: 4, Holes: []int{4}}},
+		{{Start: 0, End: 4, Holes: []int{0}}},
+		// holes with a degenerate exterior ring
+		{{Start: 0, End: 4, Holes: []int{2}}},
+		{{Start: 0, End: 4, Holes: []int{1, 2}}},
+	}
+	for _, parts := range bad {
+		if _, _, err := EarcutMulti(data, parts, 2); err == nil {
+			t.Errorf("Expected error for parts %v", parts)
+		}
+	}
+}
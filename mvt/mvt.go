@@ -0,0 +1,172 @@
+// Package mvt decodes Mapbox Vector Tile polygon geometry and triangulates
+// it with earcut.
+//
+// Only the geometry encoding is handled here; decoding the protocol buffer
+// tile itself is left to the caller, who passes in the geometry field of a
+// POLYGON feature.
+package mvt
+
+import (
+	"fmt"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// Geometry command ids
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// Polygon is a decoded polygon in tile coordinates.  Data holds 2 values
+// per vertex, exterior ring first, and HoleIndices holds the vertex index of
+// the start of each interior ring, as expected by earcut.EarcutT.
+type Polygon struct {
+	Data        []int32
+	HoleIndices []int
+}
+
+// Triangulate returns an int array of vertex indices that make up the
+// triangles of the polygon.
+func (p Polygon) Triangulate() ([]int, error) {
+	return earcut.EarcutT(p.Data, p.HoleIndices, 2)
+}
+
+// Mesh is the triangulation of all of the polygons of a feature.
+type Mesh struct {
+	// Vertices holds 2 values per vertex, in tile coordinates.
+	Vertices []int32
+
+	// Indices holds 3 vertex indices per triangle.
+	Indices []int
+
+	// Polygons holds the range of Indices belonging to each polygon.
+	Polygons []earcut.IndexRange
+}
+
+// Triangulate decodes a polygon feature's geometry and triangulates all of
+// its polygons into a single tile-local index buffer.
+func Triangulate(geom []uint32) (*Mesh, error) {
+	polys, err := DecodePolygons(geom)
+	if err != nil {
+		return nil, err
+	}
+	mesh := &Mesh{
+		Vertices: []int32{},
+		Indices:  []int{},
+		Polygons: make([]earcut.IndexRange, len(polys)),
+	}
+	for i, poly := range polys {
+		tri, err := poly.Triangulate()
+		if err != nil {
+			return nil, err
+		}
+		offset := len(mesh.Vertices) / 2
+		mesh.Vertices = append(mesh.Vertices, poly.Data...)
+		mesh.Polygons[i].Start = len(mesh.Indices)
+		for _, v := range tri {
+			mesh.Indices = append(mesh.Indices, v+offset)
+		}
+		mesh.Polygons[i].End = len(mesh.Indices)
+	}
+	return mesh, nil
+}
+
+func zigzag(v uint32) int32 {
+	return int32(v>>1) ^ -int32(v&1)
+}
+
+// DecodePolygons decodes a polygon feature's geometry into polygons.
+//
+// Following the vector tile specification, a ring with a positive area in
+// tile coordinates (clockwise, since tile y points down) is an exterior
+// ring and starts a new polygon, and a ring with a negative area is an
+// interior ring of the polygon before it.  Rings with zero area are
+// dropped.
+func DecodePolygons(geom []uint32) ([]Polygon, error) {
+	rings, err := decodeRings(geom)
+	if err != nil {
+		return nil, err
+	}
+	polys := []Polygon{}
+	for i, ring := range rings {
+		a := area(ring)
+		switch {
+		case a > 0:
+			polys = append(polys, Polygon{Data: ring, HoleIndices: []int{}})
+		case a < 0:
+			if len(polys) == 0 {
+				return nil, fmt.Errorf("mvt: ring %d is an interior ring with no exterior ring", i)
+			}
+			p := &polys[len(polys)-1]
+			p.HoleIndices = append(p.HoleIndices, len(p.Data)/2)
+			p.Data = append(p.Data, ring...)
+		}
+	}
+	return polys, nil
+}
+
+// decode the command stream into rings of flat x, y coordinates
+func decodeRings(geom []uint32) ([][]int32, error) {
+	rings := [][]int32{}
+	var ring []int32
+	var x, y int32
+	for i := 0; i < len(geom); {
+		id := geom[i] & 0x7
+		count := int(geom[i] >> 3)
+		i++
+		switch id {
+		case cmdMoveTo:
+			if count != 1 {
+				return nil, fmt.Errorf("mvt: MoveTo with count %d in polygon geometry", count)
+			}
+			if ring != nil {
+				return nil, fmt.Errorf("mvt: MoveTo before ClosePath")
+			}
+		case cmdLineTo:
+			if ring == nil {
+				return nil, fmt.Errorf("mvt: LineTo without MoveTo")
+			}
+		case cmdClosePath:
+			if count != 1 {
+				return nil, fmt.Errorf("mvt: ClosePath with count %d", count)
+			}
+			if ring == nil {
+				return nil, fmt.Errorf("mvt: ClosePath without MoveTo")
+			}
+			if len(ring) < 6 {
+				return nil, fmt.Errorf("mvt: ring with fewer than 3 points")
+			}
+			rings = append(rings, ring)
+			ring = nil
+			continue
+		default:
+			return nil, fmt.Errorf("mvt: unknown command %d", id)
+		}
+		if len(geom)-i < count*2 {
+			return nil, fmt.Errorf("mvt: geometry truncated")
+		}
+		for j := 0; j < count; j++ {
+			x += zigzag(geom[i])
+			y += zigzag(geom[i+1])
+			i += 2
+			ring = append(ring, x, y)
+		}
+	}
+	if ring != nil {
+		return nil, fmt.Errorf("mvt: ring without ClosePath")
+	}
+	return rings, nil
+}
+
+// area of a ring by the surveyor's formula; positive for exterior rings
+func area(ring []int32) int64 {
+	var sum int64
+	n := len(ring)
+	for i := 0; i < n; i += 2 {
+		j := (i + 2) % n
+		sum += int64(ring[i])*int64(ring[j+1]) - int64(ring[j])*int64(ring[i+1])
+	}
+	return sum
+}
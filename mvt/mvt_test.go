@@ -0,0 +1,115 @@
+package mvt
+
+import (
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+var epsilon = float64(1.0e-12)
+
+func command(id, count uint32) uint32 {
+	return id&0x7 | count<<3
+}
+
+func param(v int32) uint32 {
+	return uint32((v << 1) ^ (v >> 31))
+}
+
+// encode rings of absolute coordinates as a geometry command stream
+func encode(rings ...[]int32) []uint32 {
+	geom := []uint32{}
+	var x, y int32
+	for _, ring := range rings {
+		for i := 0; i < len(ring); i += 2 {
+			if i == 0 {
+				geom = append(geom, command(cmdMoveTo, 1))
+			} else if i == 2 {
+				geom = append(geom, command(cmdLineTo, uint32(len(ring)/2-1)))
+			}
+			geom = append(geom, param(ring[i]-x), param(ring[i+1]-y))
+			x, y = ring[i], ring[i+1]
+		}
+		geom = append(geom, command(cmdClosePath, 1))
+	}
+	return geom
+}
+
+func TestDecodeSpecExample(t *testing.T) {
+	// the multipolygon example from the vector tile specification
+	geom := []uint32{
+		9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+		9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+		9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+	}
+	polys, err := DecodePolygons(geom)
+	if err != nil {
+		t.Fatal("Error decoding geometry:", err)
+	}
+	if len(polys) != 2 {
+		t.Fatalf("Expected 2 polygons, got %d", len(polys))
+	}
+	exp := []int32{11, 11, 20, 11, 20, 20, 11, 20, 13, 13, 13, 17, 17, 17, 17, 13}
+	p := polys[1]
+	if len(p.Data) != len(exp) || len(p.HoleIndices) != 1 || p.HoleIndices[0] != 4 {
+		t.Fatalf("Second polygon doesn't match: %v", p)
+	}
+	for i, v := range exp {
+		if p.Data[i] != v {
+			t.Fatalf("Second polygon doesn't match: %v", p)
+		}
+	}
+	mesh, err := Triangulate(geom)
+	if err != nil {
+		t.Fatal("Error triangulating geometry:", err)
+	}
+	if len(mesh.Polygons) != 2 || len(mesh.Indices) != 6+24 {
+		t.Errorf("Expected 30 indices in 2 ranges, got %d in %d", len(mesh.Indices), len(mesh.Polygons))
+	}
+	for _, v := range mesh.Indices[mesh.Polygons[1].Start:] {
+		if v < 4 {
+			t.Errorf("Index %d not in second polygon", v)
+		}
+	}
+	r := mesh.Polygons[1]
+	local := make([]int, 0, r.End-r.Start)
+	for _, v := range mesh.Indices[r.Start:r.End] {
+		local = append(local, v-4)
+	}
+	if d := earcut.DeviationT(p.Data, p.HoleIndices, 2, local); d > epsilon {
+		t.Errorf("Triangle area not equal to polygon area (%.6f%% deviation", d*100.0)
+	}
+}
+
+func TestDecodeWinding(t *testing.T) {
+	cw := []int32{0, 0, 10, 0, 10, 10, 0, 10}
+	ccw := []int32{2, 2, 2, 4, 4, 4, 4, 2}
+	flat := []int32{0, 0, 5, 0, 10, 0}
+	polys, err := DecodePolygons(encode(cw, ccw, flat, ccw, cw))
+	if err != nil {
+		t.Fatal("Error decoding geometry:", err)
+	}
+	if len(polys) != 2 || len(polys[0].HoleIndices) != 2 || len(polys[1].HoleIndices) != 0 {
+		t.Errorf("Rings not grouped by winding: %v", polys)
+	}
+	if _, err := DecodePolygons(encode(ccw, cw)); err == nil {
+		t.Error("Expected error for interior ring before exterior ring")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	bad := [][]uint32{
+		{command(cmdLineTo, 1), 2, 2},
+		{command(cmdMoveTo, 2), 0, 0, 2, 2},
+		{command(cmdMoveTo, 1), 0, 0, command(cmdLineTo, 2), 2, 2},
+		{command(cmdMoveTo, 1), 0, 0, command(cmdLineTo, 2), 2, 0, 0, 2},
+		{command(cmdMoveTo, 1), 0, 0, command(cmdLineTo, 1), 2, 0, command(cmdClosePath, 1)},
+		{command(cmdMoveTo, 1), 0, 0, command(3, 1)},
+		{command(cmdClosePath, 1)},
+	}
+	for _, geom := range bad {
+		if _, err := DecodePolygons(geom); err == nil {
+			t.Errorf("Expected error decoding %v", geom)
+		}
+	}
+}
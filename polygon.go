@@ -0,0 +1,145 @@
+package earcut
+
+import (
+	"math"
+)
+
+// Point is a single polygon vertex.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Ring is a closed sequence of points.  The closing point may or may not be
+// repeated at the end.
+type Ring []Point
+
+// Polygon is an outer ring with zero or more holes.
+type Polygon struct {
+	Outer Ring
+	Holes []Ring
+}
+
+// Rect is an axis-aligned bounding box.
+type Rect struct {
+	Min Point
+	Max Point
+}
+
+// flatten returns the polygon as a flat vertex array with 2 values per
+// vertex, along with the vertex index of the start of each hole.  Empty
+// holes are skipped.
+func (poly Polygon) flatten() ([]float64, []int) {
+	n := len(poly.Outer)
+	for _, hole := range poly.Holes {
+		n += len(hole)
+	}
+	data := make([]float64, 0, n*2)
+	holeIndices := []int{}
+	for _, pt := range poly.Outer {
+		data = append(data, pt.X, pt.Y)
+	}
+	for _, hole := range poly.Holes {
+		if len(hole) == 0 {
+			continue
+		}
+		holeIndices = append(holeIndices, len(data)/2)
+		for _, pt := range hole {
+			data = append(data, pt.X, pt.Y)
+		}
+	}
+	return data, holeIndices
+}
+
+// Vertices returns the points of the outer ring followed by the points of
+// each hole.  This is the vertex array that the indices returned by
+// Triangulate refer to.
+func (poly Polygon) Vertices() []Point {
+	pts := make([]Point, 0, len(poly.Outer))
+	pts = append(pts, poly.Outer...)
+	for _, hole := range poly.Holes {
+		pts = append(pts, hole...)
+	}
+	return pts
+}
+
+// Triangulate returns an int array of vertex indices that make up the
+// triangles of the polygon.  Indices refer to the points returned by
+// Vertices.
+func (poly Polygon) Triangulate() ([]int, error) {
+	data, holeIndices := poly.flatten()
+	return Earcut(data, holeIndices, 2)
+}
+
+// Area returns the area of the outer ring minus the area of the holes.
+func (poly Polygon) Area() float64 {
+	data, holeIndices := poly.flatten()
+	return polygonArea(data, holeIndices, 2)
+}
+
+// Bounds returns the bounding box of the outer ring.  The bounds of an empty
+// polygon have Min at +Inf and Max at -Inf.
+func (poly Polygon) Bounds() Rect {
+	r := Rect{
+		Min: Point{math.Inf(1), math.Inf(1)},
+		Max: Point{math.Inf(-1), math.Inf(-1)},
+	}
+	for _, pt := range poly.Outer {
+		r.Min.X = math.Min(r.Min.X, pt.X)
+		r.Min.Y = math.Min(r.Min.Y, pt.Y)
+		r.Max.X = math.Max(r.Max.X, pt.X)
+		r.Max.Y = math.Max(r.Max.Y, pt.Y)
+	}
+	return r
+}
+
+// Contains reports whether a point lies inside the polygon, i.e. inside the
+// outer ring and outside all of the holes.  Points exactly on an edge may
+// be reported either way.
+func (poly Polygon) Contains(pt Point) bool {
+	data, holeIndices := poly.flatten()
+	return polygonContains(data, holeIndices, 2, pt.X, pt.Y)
+}
+
+// whether a point is inside an odd number of rings
+func polygonContains(data []float64, holeIndices []int, dim int, x, y float64) bool {
+	inside := false
+	for i, l := 0, len(holeIndices); i <= l; i++ {
+		start, end := ringRange(data, holeIndices, dim, i)
+		ring := linkedList(data, start, end, dim, true)
+		if ring != nil && pointInRing(ring, x, y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ringRange returns the data range of ring i, where ring 0 is the outer ring
+// and ring i > 0 is hole i-1
+func ringRange(data []float64, holeIndices []int, dim, i int) (int, int) {
+	var start, end int
+	if i > 0 {
+		start = holeIndices[i-1] * dim
+	}
+	if i < len(holeIndices) {
+		end = holeIndices[i] * dim
+	} else {
+		end = len(data)
+	}
+	return start, end
+}
+
+// area of the outer ring minus the area of the holes
+func polygonArea(data []float64, holeIndices []int, dim int) float64 {
+	var sum float64
+	for i, l := 0, len(holeIndices); i <= l; i++ {
+		start, end := ringRange(data, holeIndices, dim, i)
+		a := math.Abs(signedArea(data, start, end, dim))
+		if i == 0 {
+			sum += a
+		} else {
+			sum -= a
+		}
+	}
+	return sum / 2.0
+}
@@ -0,0 +1,72 @@
+package earcut
+
+import (
+	"math"
+	"testing"
+)
+
+func squareWithHole() Polygon {
+	return Polygon{
+		Outer: Ring{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		Holes: []Ring{
+			{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+		},
+	}
+}
+
+func TestPolygonTriangulate(t *testing.T) {
+	poly := squareWithHole()
+	tri, err := poly.Triangulate()
+	if err != nil {
+		t.Error("Error making triangles for a polygon:", err)
+	}
+	if len(tri) != 24 {
+		t.Errorf("Expected 24 vertex indices, got %d", len(tri))
+	}
+	verts := poly.Vertices()
+	if len(verts) != 10 {
+		t.Errorf("Expected 10 vertices, got %d", len(verts))
+	}
+	var sum float64
+	for i := 0; i < len(tri); i += 3 {
+		a, b, c := verts[tri[i]], verts[tri[i+1]], verts[tri[i+2]]
+		sum += math.Abs((b.X-a.X)*(c.Y-a.Y)-(c.X-a.X)*(b.Y-a.Y)) / 2.0
+	}
+	if math.Abs(sum-poly.Area()) > epsilon {
+		t.Errorf("Triangle area %f not equal to polygon area %f", sum, poly.Area())
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	poly := squareWithHole()
+	if a := poly.Area(); a != 15.0 {
+		t.Errorf("Expected area 15, got %f", a)
+	}
+}
+
+func TestPolygonBounds(t *testing.T) {
+	poly := squareWithHole()
+	exp := Rect{Min: Point{0, 0}, Max: Point{4, 4}}
+	if b := poly.Bounds(); b != exp {
+		t.Errorf("Expected bounds %v, got %v", exp, b)
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	poly := squareWithHole()
+	tests := []struct {
+		pt  Point
+		exp bool
+	}{
+		{Point{0.5, 0.5}, true},
+		{Point{3, 3}, true},
+		{Point{1.5, 1.5}, false},
+		{Point{5, 1}, false},
+		{Point{-1, -1}, false},
+	}
+	for _, test := range tests {
+		if test.exp != poly.Contains(test.pt) {
+			t.Errorf("Expected Contains(%v) == %t", test.pt, test.exp)
+		}
+	}
+}
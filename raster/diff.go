@@ -0,0 +1,40 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+)
+
+// Diff compares two images pixel by pixel over the intersection of their
+// bounds.  A pixel differs if any of its channels differ by more than
+// tolerance, on the 8-bit scale.  It returns the number of differing
+// pixels and an image with the differing pixels in red over a faded copy
+// of a, for writing out when a test fails.
+func Diff(a, b image.Image, tolerance uint8) (int, *image.RGBA) {
+	r := a.Bounds().Intersect(b.Bounds())
+	out := image.NewRGBA(r)
+	tol := uint32(tolerance) * 0x101
+	count := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			ar, ag, ab, aa := a.At(x, y).RGBA()
+			br, bg, bb, ba := b.At(x, y).RGBA()
+			if absDiff(ar, br) > tol || absDiff(ag, bg) > tol || absDiff(ab, bb) > tol || absDiff(aa, ba) > tol {
+				count++
+				out.SetRGBA(x, y, color.RGBA{0xff, 0, 0, 0xff})
+				continue
+			}
+			g := color.GrayModel.Convert(a.At(x, y)).(color.Gray).Y
+			g = 0xc0 + g/4
+			out.SetRGBA(x, y, color.RGBA{g, g, g, 0xff})
+		}
+	}
+	return count, out
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,32 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := image.NewGray(image.Rect(0, 0, 4, 4))
+	b := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range b.Pix {
+		if i%4 == 3 {
+			b.Pix[i] = 0xff
+		}
+	}
+	if n, _ := Diff(a, b, 0); n != 0 {
+		t.Errorf("Expected no differences, got %d", n)
+	}
+	a.SetGray(1, 2, color.Gray{3})
+	a.SetGray(3, 3, color.Gray{0x40})
+	if n, _ := Diff(a, b, 3); n != 1 {
+		t.Errorf("Expected 1 difference, got %d", n)
+	}
+	n, diff := Diff(a, b, 0)
+	if n != 2 {
+		t.Errorf("Expected 2 differences, got %d", n)
+	}
+	if diff.RGBAAt(3, 3) != (color.RGBA{0xff, 0, 0, 0xff}) || diff.RGBAAt(0, 0).R != diff.RGBAAt(0, 0).G {
+		t.Error("Diff image doesn't match")
+	}
+}
@@ -0,0 +1,278 @@
+// Package raster draws polygons and earcut triangulations into images, so
+// that a triangulation can be checked by comparing pixels with the polygon
+// it came from rather than by comparing index lists.
+//
+// FillPolygon fills the rings of a polygon under the even-odd rule, and
+// FillTriangles fills a triangle list.  Both sample the same points and
+// treat edges the same way (a sample exactly on an edge belongs to the
+// shape on its right, and to the shape below a horizontal edge), so an
+// exact triangulation of a polygon covers exactly the same samples as
+// the polygon itself, and shared triangle edges are neither doubled nor
+// left open.
+package raster
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// Transform maps polygon coordinates to pixel coordinates:
+//
+//	px = (x - X0) * Scale
+//	py = (Y0 - y) * Scale
+//
+// so that y runs up the image, as it does on a map.
+type Transform struct {
+	X0    float64
+	Y0    float64
+	Scale float64
+}
+
+// Fit returns a Transform that scales the vertices to fit inside an image
+// of the given size, less margin pixels on each side, keeping the aspect
+// ratio and centering the vertices.
+func Fit(data []float64, dim int, width, height int, margin float64) Transform {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i+1 < len(data); i += dim {
+		minX = math.Min(minX, data[i])
+		minY = math.Min(minY, data[i+1])
+		maxX = math.Max(maxX, data[i])
+		maxY = math.Max(maxY, data[i+1])
+	}
+	if minX > maxX {
+		return Transform{Scale: 1}
+	}
+	w := float64(width) - 2*margin
+	h := float64(height) - 2*margin
+	scale := math.Inf(1)
+	if maxX > minX {
+		scale = w / (maxX - minX)
+	}
+	if maxY > minY {
+		scale = math.Min(scale, h/(maxY-minY))
+	}
+	if math.IsInf(scale, 1) {
+		scale = 1
+	}
+	return Transform{
+		X0:    (minX+maxX)/2 - float64(width)/2/scale,
+		Y0:    (minY+maxY)/2 + float64(height)/2/scale,
+		Scale: scale,
+	}
+}
+
+// Apply maps a point to pixel coordinates.
+func (t Transform) Apply(x, y float64) (float64, float64) {
+	return (x - t.X0) * t.Scale, (t.Y0 - y) * t.Scale
+}
+
+// Options controls how shapes are drawn.
+type Options struct {
+	Transform Transform
+
+	// Antialias blends the color into pixels that are only partly
+	// covered, by sampling each pixel on a 4x4 grid.  Otherwise each
+	// pixel is sampled once, at its center.
+	Antialias bool
+}
+
+const aaSamples = 4
+
+// edge is a line segment in pixel coordinates, with y0 <= y1
+type edge struct {
+	x0, y0, x1, y1 float64
+}
+
+func newEdge(ax, ay, bx, by float64) edge {
+	// order the ends the same way for every shape that shares the edge,
+	// so crossings are computed identically
+	if by < ay || (by == ay && bx < ax) {
+		ax, ay, bx, by = bx, by, ax, ay
+	}
+	return edge{ax, ay, bx, by}
+}
+
+// coverage counts the samples covered in each pixel of an image
+type coverage struct {
+	rect   image.Rectangle
+	ss     int
+	counts []uint16
+	xs     []float64
+}
+
+func newCoverage(r image.Rectangle, antialias bool) *coverage {
+	ss := 1
+	if antialias {
+		ss = aaSamples
+	}
+	return &coverage{
+		rect:   r,
+		ss:     ss,
+		counts: make([]uint16, r.Dx()*r.Dy()),
+	}
+}
+
+// fill adds the samples inside a closed set of edges, under the even-odd
+// rule
+func (c *coverage) fill(edges []edge) {
+	if len(edges) == 0 {
+		return
+	}
+	ss := float64(c.ss)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, e := range edges {
+		minY = math.Min(minY, e.y0)
+		maxY = math.Max(maxY, e.y1)
+	}
+	// sample rows are at (j + 0.5) / ss
+	j0 := int(math.Max(math.Ceil(minY*ss-0.5), float64(c.rect.Min.Y*c.ss)))
+	j1 := int(math.Min(math.Ceil(maxY*ss-0.5), float64(c.rect.Max.Y*c.ss)))
+	for j := j0; j < j1; j++ {
+		y := (float64(j) + 0.5) / ss
+		xs := c.xs[:0]
+		for _, e := range edges {
+			if e.y0 <= y && y < e.y1 {
+				xs = append(xs, e.x0+(y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0))
+			}
+		}
+		sort.Float64s(xs)
+		row := (floorDiv(j, c.ss) - c.rect.Min.Y) * c.rect.Dx()
+		for k := 0; k+1 < len(xs); k += 2 {
+			i0 := int(math.Max(math.Ceil(xs[k]*ss-0.5), float64(c.rect.Min.X*c.ss)))
+			i1 := int(math.Min(math.Ceil(xs[k+1]*ss-0.5), float64(c.rect.Max.X*c.ss)))
+			for i := i0; i < i1; i++ {
+				c.counts[row+floorDiv(i, c.ss)-c.rect.Min.X]++
+			}
+		}
+		c.xs = xs
+	}
+}
+
+// floorDiv divides rounding down, so that samples left of or above the
+// origin land in the right pixel
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((b - 1 - a) / b)
+	}
+	return a / b
+}
+
+// draw blends a color into the image in proportion to the coverage of
+// each pixel
+func (c *coverage) draw(img draw.Image, col color.Color) {
+	full := uint32(c.ss * c.ss)
+	cr, cg, cb, ca := col.RGBA()
+	gray, _ := img.(*image.Gray)
+	rgba, _ := img.(*image.RGBA)
+	// premultiplied gray level, as in color.Gray16Model
+	gy := (19595*cr + 38470*cg + 7471*cb + 1<<15) >> 16
+	for y := c.rect.Min.Y; y < c.rect.Max.Y; y++ {
+		row := (y - c.rect.Min.Y) * c.rect.Dx()
+		for x := c.rect.Min.X; x < c.rect.Max.X; x++ {
+			n := uint32(c.counts[row+x-c.rect.Min.X])
+			if n == 0 {
+				continue
+			}
+			if n > full {
+				n = full
+			}
+			// a is the coverage scaled to 0..0xffff, and ma the
+			// remaining weight of the existing pixel
+			a := ca * n / full
+			ma := 0xffff - a
+			switch {
+			case gray != nil:
+				i := gray.PixOffset(x, y)
+				gray.Pix[i] = uint8((uint32(gray.Pix[i])*0x101*ma/0xffff + gy*n/full) >> 8)
+			case rgba != nil:
+				i := rgba.PixOffset(x, y)
+				p := rgba.Pix[i : i+4 : i+4]
+				p[0] = uint8((uint32(p[0])*0x101*ma/0xffff + cr*n/full) >> 8)
+				p[1] = uint8((uint32(p[1])*0x101*ma/0xffff + cg*n/full) >> 8)
+				p[2] = uint8((uint32(p[2])*0x101*ma/0xffff + cb*n/full) >> 8)
+				p[3] = uint8((uint32(p[3])*0x101*ma/0xffff + a) >> 8)
+			default:
+				dr, dg, db, da := img.At(x, y).RGBA()
+				img.Set(x, y, color.RGBA64{
+					R: uint16(dr*ma/0xffff + cr*n/full),
+					G: uint16(dg*ma/0xffff + cg*n/full),
+					B: uint16(db*ma/0xffff + cb*n/full),
+					A: uint16(da*ma/0xffff + a),
+				})
+			}
+		}
+	}
+}
+
+// FillTriangles draws the triangles in a color.  The data, dim and
+// triangles are as passed to and returned by earcut.
+func FillTriangles(img draw.Image, data []float64, dim int, triangles []int, col color.Color, opts Options) error {
+	if dim < 2 {
+		return errors.New("need at least 2 dimensions")
+	}
+	if len(triangles)%3 != 0 {
+		return errors.New("triangle index count is not a multiple of 3")
+	}
+	n := len(data) / dim
+	c := newCoverage(img.Bounds(), opts.Antialias)
+	edges := make([]edge, 3)
+	for i := 0; i < len(triangles); i += 3 {
+		var px, py [3]float64
+		for k := 0; k < 3; k++ {
+			v := triangles[i+k]
+			if v < 0 || v >= n {
+				return errors.New("vertex index out of range")
+			}
+			px[k], py[k] = opts.Transform.Apply(data[v*dim], data[v*dim+1])
+		}
+		for k := 0; k < 3; k++ {
+			edges[k] = newEdge(px[k], py[k], px[(k+1)%3], py[(k+1)%3])
+		}
+		c.fill(edges)
+	}
+	c.draw(img, col)
+	return nil
+}
+
+// FillPolygon draws a polygon in a color, under the even-odd rule: a
+// point is inside if it is inside an odd number of rings.  The data,
+// holeIndices and dim are as passed to earcut.
+func FillPolygon(img draw.Image, data []float64, holeIndices []int, dim int, col color.Color, opts Options) error {
+	if dim < 2 {
+		return errors.New("need at least 2 dimensions")
+	}
+	edges := []edge{}
+	for r := 0; r <= len(holeIndices); r++ {
+		start := 0
+		if r > 0 {
+			start = holeIndices[r-1] * dim
+		}
+		end := len(data)
+		if r < len(holeIndices) {
+			end = holeIndices[r] * dim
+		}
+		if start < 0 || end > len(data) || start > end {
+			return errors.New("hole index out of range")
+		}
+		if end-start < dim {
+			continue
+		}
+		// rings close themselves; a repeated closing point just adds an
+		// empty edge
+		lx, ly := opts.Transform.Apply(data[end-dim], data[end-dim+1])
+		for i := start; i+1 < end; i += dim {
+			x, y := opts.Transform.Apply(data[i], data[i+1])
+			edges = append(edges, newEdge(lx, ly, x, y))
+			lx, ly = x, y
+		}
+	}
+	c := newCoverage(img.Bounds(), opts.Antialias)
+	c.fill(edges)
+	c.draw(img, col)
+	return nil
+}
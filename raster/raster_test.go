@@ -0,0 +1,222 @@
+package raster
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// identity transform for a 10x10 image, with y up
+var unit = Transform{X0: 0, Y0: 10, Scale: 1}
+
+func countGray(img *image.Gray, v uint8) int {
+	n := 0
+	for _, p := range img.Pix {
+		if p == v {
+			n++
+		}
+	}
+	return n
+}
+
+func loadFixture(name string, t *testing.T) ([]float64, []int) {
+	raw, err := ioutil.ReadFile(filepath.Join("..", "fixtures", name+".json"))
+	if err != nil {
+		t.Fatal("Error reading fixture data:", err)
+	}
+	rings := [][][2]float64{}
+	if err = json.Unmarshal(raw, &rings); err != nil {
+		t.Fatal("Error unmarshaling json fixture data:", err)
+	}
+	data, holeIndices := earcut.Flatten(rings)
+	return data, holeIndices
+}
+
+// writePNG saves an image to the temp directory for inspection
+func writePNG(name string, img image.Image, t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "go-earcut")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Log("Error creating debug output directory:", err)
+		return
+	}
+	fn := filepath.Join(dir, name+".png")
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Log("Error creating debug image:", err)
+		return
+	}
+	defer f.Close()
+	if err = png.Encode(f, img); err != nil {
+		t.Log("Error writing debug image:", err)
+		return
+	}
+	t.Log("Wrote debug image to", fn)
+}
+
+func TestFillTriangles(t *testing.T) {
+	square := []float64{2, 2, 8, 2, 8, 8, 2, 8}
+	tri := []int{0, 1, 2, 2, 3, 0}
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	if err := FillTriangles(img, square, 2, tri, color.White, Options{Transform: unit}); err != nil {
+		t.Fatal("Error filling triangles:", err)
+	}
+	if n := countGray(img, 0xff); n != 36 {
+		t.Errorf("Expected 36 filled pixels, got %d", n)
+	}
+	if img.GrayAt(1, 1).Y != 0 || img.GrayAt(2, 2).Y != 0xff || img.GrayAt(7, 7).Y != 0xff || img.GrayAt(8, 8).Y != 0 {
+		t.Error("Filled pixels are in the wrong place")
+	}
+
+	// the shared diagonal is neither doubled nor missed, even antialiased
+	img = image.NewGray(image.Rect(0, 0, 10, 10))
+	FillTriangles(img, square, 2, tri, color.White, Options{Transform: unit, Antialias: true})
+	if n := countGray(img, 0xff); n != 36 {
+		t.Errorf("Expected 36 filled pixels, got %d", n)
+	}
+
+	// half covered pixels get half the color
+	half := []float64{0, 0, 2.5, 0, 2.5, 10, 0, 10}
+	img = image.NewGray(image.Rect(0, 0, 10, 10))
+	FillTriangles(img, half, 2, tri, color.White, Options{Transform: unit, Antialias: true})
+	if v := img.GrayAt(2, 5).Y; v != 0x7f {
+		t.Errorf("Expected gray 0x7f at the edge, got %#x", v)
+	}
+	if v := img.GrayAt(1, 5).Y; v != 0xff {
+		t.Errorf("Expected gray 0xff inside, got %#x", v)
+	}
+	img = image.NewGray(image.Rect(0, 0, 10, 10))
+	FillTriangles(img, half, 2, tri, color.White, Options{Transform: unit})
+	if n := countGray(img, 0xff); n != 20 {
+		t.Errorf("Expected 20 filled pixels, got %d", n)
+	}
+
+	if err := FillTriangles(img, square, 2, []int{0, 1, 4}, color.White, Options{Transform: unit}); err == nil {
+		t.Error("Expected error for bad vertex index")
+	}
+	if err := FillTriangles(img, square, 2, []int{0, 1}, color.White, Options{Transform: unit}); err == nil {
+		t.Error("Expected error for partial triangle")
+	}
+}
+
+func TestFillColorModels(t *testing.T) {
+	square := []float64{2, 2, 8, 2, 8, 8, 2, 8}
+	tri := []int{0, 1, 2, 2, 3, 0}
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	opts := Options{Transform: unit, Antialias: true}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	FillTriangles(rgba, square, 2, tri, red, opts)
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	FillTriangles(nrgba, square, 2, tri, red, opts)
+	if n, _ := Diff(rgba, nrgba, 0); n != 0 {
+		t.Errorf("RGBA and NRGBA images differ in %d pixels", n)
+	}
+	if rgba.RGBAAt(5, 5) != red || rgba.RGBAAt(0, 0) != (color.RGBA{}) {
+		t.Error("RGBA pixels don't match")
+	}
+
+	// half transparent white over black
+	gray := image.NewGray(image.Rect(0, 0, 10, 10))
+	FillTriangles(gray, square, 2, tri, color.NRGBA{0xff, 0xff, 0xff, 0x80}, opts)
+	if v := gray.GrayAt(5, 5).Y; v != 0x80 {
+		t.Errorf("Expected gray 0x80, got %#x", v)
+	}
+}
+
+func TestFillPolygon(t *testing.T) {
+	// a square with a square hole, with closing points
+	data := []float64{
+		1, 1, 9, 1, 9, 9, 1, 9, 1, 1,
+		3, 3, 3, 7, 7, 7, 7, 3, 3, 3,
+	}
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	if err := FillPolygon(img, data, []int{5}, 2, color.White, Options{Transform: unit}); err != nil {
+		t.Fatal("Error filling polygon:", err)
+	}
+	if n := countGray(img, 0xff); n != 48 {
+		t.Errorf("Expected 48 filled pixels, got %d", n)
+	}
+	if img.GrayAt(5, 5).Y != 0 || img.GrayAt(1, 1).Y != 0xff {
+		t.Error("Filled pixels are in the wrong place")
+	}
+	if err := FillPolygon(img, data, []int{20}, 2, color.White, Options{Transform: unit}); err == nil {
+		t.Error("Expected error for bad hole index")
+	}
+}
+
+func TestFit(t *testing.T) {
+	data := []float64{-10, 0, 10, 0, 10, 5, -10, 5}
+	tr := Fit(data, 2, 110, 60, 5)
+	x, y := tr.Apply(-10, 5)
+	if x != 5 || y != 17.5 {
+		t.Errorf("Expected (5, 17.5), got (%g, %g)", x, y)
+	}
+	x, y = tr.Apply(10, 0)
+	if x != 105 || y != 42.5 {
+		t.Errorf("Expected (105, 42.5), got (%g, %g)", x, y)
+	}
+}
+
+// testCoverage checks that the triangles cover exactly the same pixels as
+// the polygon
+func testCoverage(name string, maxDiff int, t *testing.T) {
+	data, holeIndices := loadFixture(name, t)
+	tri, err := earcut.Earcut(data, holeIndices, 2)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	for _, aa := range []bool{false, true} {
+		opts := Options{Transform: Fit(data, 2, 256, 256, 2), Antialias: aa}
+		poly := image.NewGray(image.Rect(0, 0, 256, 256))
+		if err = FillPolygon(poly, data, holeIndices, 2, color.White, opts); err != nil {
+			t.Fatal("Error filling polygon:", err)
+		}
+		tris := image.NewGray(image.Rect(0, 0, 256, 256))
+		if err = FillTriangles(tris, data, 2, tri, color.White, opts); err != nil {
+			t.Fatal("Error filling triangles:", err)
+		}
+		if n, diff := Diff(poly, tris, 0); n > maxDiff {
+			t.Errorf("Triangles differ from polygon in %d pixels for %s (antialias %t)", n, name, aa)
+			writePNG(name+"-polygon", poly, t)
+			writePNG(name+"-triangles", tris, t)
+			writePNG(name+"-diff", diff, t)
+		}
+	}
+}
+
+func TestCoverageBuilding(t *testing.T) {
+	testCoverage("building", 0, t)
+}
+
+func TestCoverageDude(t *testing.T) {
+	testCoverage("dude", 0, t)
+}
+
+// water and bad-hole aren't triangulated exactly; see their Deviation
+// tests
+func TestCoverageWater(t *testing.T) {
+	testCoverage("water", 25, t)
+}
+
+func TestCoverageWater2(t *testing.T) {
+	testCoverage("water2", 0, t)
+}
+
+func TestCoverageHilbert(t *testing.T) {
+	testCoverage("hilbert", 0, t)
+}
+
+func TestCoverageHoleTouchingOuter(t *testing.T) {
+	testCoverage("hole-touching-outer", 0, t)
+}
+
+func TestCoverageBadHole(t *testing.T) {
+	testCoverage("bad-hole", 400, t)
+}
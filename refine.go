@@ -0,0 +1,418 @@
+package earcut
+
+import (
+	"math"
+)
+
+// RefineOptions controls Refine.
+type RefineOptions struct {
+	// MinAngle is the smallest angle, in degrees, wanted in any
+	// triangle, or 0 for no limit.  Refinement is only guaranteed to
+	// finish for angles up to about 20 degrees; larger values usually
+	// work but may use up MaxSteiner.  Triangles in the corner between
+	// two ring edges that meet at a smaller angle are left alone, as
+	// they can't be improved.
+	MinAngle float64
+
+	// MaxArea is the largest area wanted for any triangle, or 0 for no
+	// limit.
+	MaxArea float64
+
+	// MaxSteiner limits the number of vertices inserted.  If 0, the limit
+	// is 100 times the number of input vertices, or 10000, whichever is
+	// larger.
+	MaxSteiner int
+}
+
+// EarcutRefine triangulates a polygon with Earcut, then improves the
+// triangles with Refine.  The arguments are as for Earcut.
+func EarcutRefine(data []float64, holeIndices []int, dim int, opts RefineOptions) ([]float64, []int, error) {
+	triangles, err := Earcut(data, holeIndices, dim)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, triangles = Refine(data, holeIndices, dim, triangles, opts)
+	return data, triangles, nil
+}
+
+// Refine inserts Steiner points into a triangulation until every triangle
+// meets the minimum angle and maximum area in opts, using Ruppert's
+// algorithm: the triangulation is kept constrained Delaunay (see
+// Delaunay), ring edges with a vertex inside their diametral circle are
+// split at their midpoints, and bad triangles are split at their
+// circumcenters.
+//
+// The data, holeIndices, dim and triangles are as for Deviation.  Refine
+// returns a new vertex array, holding the input vertices followed by the
+// inserted ones, and the triangles of the refined mesh.  Values beyond x
+// and y in the new vertices are interpolated from the vertices around
+// them.  The input is left unchanged.
+func Refine(data []float64, holeIndices []int, dim int, triangles []int, opts RefineOptions) ([]float64, []int) {
+	r := newRefiner(data, holeIndices, dim, Delaunay(data, holeIndices, dim, triangles), opts)
+	r.refine()
+	return r.data, r.he.Triangles
+}
+
+// refiner is a triangulation, along with its ring segments, that new
+// vertices can be added to
+type refiner struct {
+	*cdt
+	data []float64
+	dim  int
+	opts RefineOptions
+
+	// orient is the sign of orient2d for the triangles, which all wind
+	// the same way
+	orient float64
+
+	// budget is the number of vertices that may still be inserted, and
+	// minLen2 the squared length of the shortest edge worth splitting
+	budget  int
+	minLen2 float64
+
+	// sinMin is the sine of opts.MinAngle
+	sinMin float64
+}
+
+func newRefiner(data []float64, holeIndices []int, dim int, triangles []int, opts RefineOptions) *refiner {
+	r := &refiner{
+		cdt:  newCDT(data, holeIndices, dim, triangles),
+		data: make([]float64, len(data)),
+		dim:  dim,
+		opts: opts,
+	}
+	copy(r.data, data)
+	n := len(data) / dim
+	r.budget = opts.MaxSteiner
+	if r.budget <= 0 {
+		r.budget = 100 * n
+		if r.budget < 10000 {
+			r.budget = 10000
+		}
+	}
+	if opts.MinAngle > 0 {
+		r.sinMin = math.Sin(math.Min(opts.MinAngle, 60) * math.Pi / 180)
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range r.nodes {
+		minX = math.Min(minX, p.x)
+		minY = math.Min(minY, p.y)
+		maxX = math.Max(maxX, p.x)
+		maxY = math.Max(maxY, p.y)
+	}
+	size := math.Max(maxX-minX, maxY-minY) * 1e-9
+	r.minLen2 = size * size
+	r.orient = r.orientation()
+	return r
+}
+
+// orientation returns the sign of orient2d for the triangles, taken from
+// the first one that isn't flat, or 0 if they all are
+func (m *cdt) orientation() float64 {
+	tri := m.he.Triangles
+	for i := 0; i < len(tri); i += 3 {
+		a, b, c := m.nodes[tri[i]], m.nodes[tri[i+1]], m.nodes[tri[i+2]]
+		o := orient2d(a.x, a.y, b.x, b.y, c.x, c.y)
+		if o > 0 {
+			return 1
+		} else if o < 0 {
+			return -1
+		}
+	}
+	return 0
+}
+
+// refine splits encroached segments and bad triangles until there are none
+// left, or the budget runs out
+func (r *refiner) refine() {
+	if r.orient == 0 || (r.opts.MinAngle <= 0 && r.opts.MaxArea <= 0) {
+		return
+	}
+	for changed := true; changed && r.budget > 0; {
+		changed = false
+		// segments come first, as in Ruppert's algorithm; the list of
+		// half-edges grows as segments are split, and the new halves
+		// are checked as well
+		for h := 0; h < len(r.he.Triangles) && r.budget > 0; h++ {
+			if r.isSegment(h) && r.encroached(h) && r.splitSegment(h) {
+				changed = true
+			}
+		}
+		if changed {
+			continue
+		}
+		for t := 0; t < len(r.he.Triangles)/3 && r.budget > 0; t++ {
+			if r.bad(t*3) && r.splitBad(t*3) {
+				changed = true
+			}
+		}
+	}
+}
+
+// isSegment reports whether half-edge h lies along the input rings.  Edges
+// on the boundary of the triangulation count even if they span several
+// collinear ring edges.
+func (r *refiner) isSegment(h int) bool {
+	return r.he.Twin[h] < 0 || r.isFixed(h)
+}
+
+// encroached reports whether the vertex opposite segment h, on either side,
+// lies inside the segment's diametral circle
+func (r *refiner) encroached(h int) bool {
+	a, b := r.nodes[r.he.Origin(h)], r.nodes[r.he.Dest(h)]
+	for _, e := range []int{h, r.he.Twin[h]} {
+		if e < 0 {
+			continue
+		}
+		c := r.nodes[r.he.Triangles[r.he.Prev(e)]]
+		if (a.x-c.x)*(b.x-c.x)+(a.y-c.y)*(b.y-c.y) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// bad reports whether the triangle starting at half-edge t is too big or
+// too thin
+func (r *refiner) bad(t int) bool {
+	tri := r.he.Triangles
+	p := [3]*node{r.nodes[tri[t]], r.nodes[tri[t+1]], r.nodes[tri[t+2]]}
+	area := orient2d(p[0].x, p[0].y, p[1].x, p[1].y, p[2].x, p[2].y) * r.orient / 2
+	if area <= 0 {
+		return false
+	}
+	if r.opts.MaxArea > 0 && area > r.opts.MaxArea {
+		return true
+	}
+	if r.sinMin == 0 {
+		return false
+	}
+	// the smallest angle is at vertex k, opposite the shortest edge
+	var l2 [3]float64
+	k := 0
+	for i := 0; i < 3; i++ {
+		a, b := p[(i+1)%3], p[(i+2)%3]
+		l2[i] = (a.x-b.x)*(a.x-b.x) + (a.y-b.y)*(a.y-b.y)
+		if l2[i] < l2[k] {
+			k = i
+		}
+	}
+	if l2[k] < r.minLen2 {
+		return false
+	}
+	sin := 2 * area / math.Sqrt(l2[(k+1)%3]*l2[(k+2)%3])
+	if sin >= r.sinMin {
+		return false
+	}
+	// a small angle between two segments at an input vertex is there
+	// to stay
+	if !p[k].steiner && r.isSegment(t+k) && r.isSegment(t+(k+2)%3) {
+		return false
+	}
+	return true
+}
+
+// splitBad inserts the circumcenter of the triangle starting at half-edge
+// t, or splits a segment that the circumcenter would encroach on.  It
+// reports whether the mesh changed.
+func (r *refiner) splitBad(t int) bool {
+	tri := r.he.Triangles
+	a, b, c := r.nodes[tri[t]], r.nodes[tri[t+1]], r.nodes[tri[t+2]]
+	bx, by := b.x-a.x, b.y-a.y
+	cx, cy := c.x-a.x, c.y-a.y
+	d := 2 * (bx*cy - by*cx)
+	if d == 0 {
+		return false
+	}
+	bl, cl := bx*bx+by*by, cx*cx+cy*cy
+	x := a.x + (cy*bl-by*cl)/d
+	y := a.y + (bx*cl-cx*bl)/d
+
+	f, h := r.locate(t, x, y)
+	if f < 0 {
+		// the circumcenter is outside the polygon, or hidden behind a
+		// segment; split that segment instead
+		return h >= 0 && r.splitSegment(h)
+	}
+
+	// don't insert a vertex that encroaches on a nearby segment
+	for k := 0; k < 3; k++ {
+		for _, e := range []int{f + k, r.he.Twin[f+k]} {
+			if e < 0 {
+				continue
+			}
+			for _, s := range []int{e, r.he.Next(e), r.he.Prev(e)} {
+				p, q := r.nodes[r.he.Origin(s)], r.nodes[r.he.Dest(s)]
+				if r.isSegment(s) && (p.x-x)*(q.x-x)+(p.y-y)*(q.y-y) < 0 {
+					return r.splitSegment(s)
+				}
+			}
+		}
+	}
+
+	verts := []int{tri[f], tri[f+1], tri[f+2]}
+	for _, v := range verts {
+		p := r.nodes[v]
+		if (p.x-x)*(p.x-x)+(p.y-y)*(p.y-y) < r.minLen2 {
+			return false
+		}
+	}
+	if h >= 0 {
+		p, q := r.nodes[r.he.Origin(h)], r.nodes[r.he.Dest(h)]
+		s := ((x-p.x)*(q.x-p.x) + (y-p.y)*(q.y-p.y)) / ((q.x-p.x)*(q.x-p.x) + (q.y-p.y)*(q.y-p.y))
+		v := r.addVertex(x, y, []int{r.he.Origin(h), r.he.Dest(h)}, []float64{1 - s, s})
+		r.legalize(r.splitEdge(h, v))
+		return true
+	}
+	p0, p1, p2 := r.nodes[verts[0]], r.nodes[verts[1]], r.nodes[verts[2]]
+	area := orient2d(p0.x, p0.y, p1.x, p1.y, p2.x, p2.y)
+	w := []float64{
+		orient2d(x, y, p1.x, p1.y, p2.x, p2.y) / area,
+		orient2d(p0.x, p0.y, x, y, p2.x, p2.y) / area,
+		orient2d(p0.x, p0.y, p1.x, p1.y, x, y) / area,
+	}
+	v := r.addVertex(x, y, verts, w)
+	r.legalize(r.splitTriangle(f, v))
+	return true
+}
+
+// locate walks from the triangle starting at half-edge t towards the point
+// (x, y).  It returns the first half-edge of the triangle containing the
+// point, along with the half-edge the point lies on, or -1.  If the walk
+// is stopped by a segment, it returns -1 and the segment's half-edge.
+func (r *refiner) locate(t int, x, y float64) (int, int) {
+	tri := r.he.Triangles
+	for steps := len(tri); steps > 0; steps-- {
+		on := -1
+		moved := false
+		for k := 0; k < 3; k++ {
+			h := t + k
+			a, b := r.nodes[tri[h]], r.nodes[tri[r.he.Next(h)]]
+			o := orient2d(a.x, a.y, b.x, b.y, x, y) * r.orient
+			l2 := (b.x-a.x)*(b.x-a.x) + (b.y-a.y)*(b.y-a.y)
+			if math.Abs(o) <= l2*1e-12 {
+				on = h
+				continue
+			}
+			if o < 0 {
+				if r.isSegment(h) {
+					return -1, h
+				}
+				tw := r.he.Twin[h]
+				t = tw - tw%3
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			if on >= 0 && r.isSegment(on) {
+				return -1, on
+			}
+			return t, on
+		}
+	}
+	return -1, -1
+}
+
+// addVertex appends a Steiner point at (x, y), with any further values
+// interpolated from the given vertices and weights
+func (r *refiner) addVertex(x, y float64, verts []int, weights []float64) int {
+	v := len(r.nodes)
+	r.data = append(r.data, x, y)
+	for k := 2; k < r.dim; k++ {
+		var sum float64
+		for i, u := range verts {
+			sum += r.data[u*r.dim+k] * weights[i]
+		}
+		r.data = append(r.data, sum)
+	}
+	p := newNode(v*r.dim, x, y)
+	p.steiner = true
+	r.nodes = append(r.nodes, p)
+	r.weld = append(r.weld, v)
+	r.budget--
+	return v
+}
+
+// splitSegment splits the segment of half-edge h at its midpoint, if it's
+// long enough
+func (r *refiner) splitSegment(h int) bool {
+	a, b := r.he.Origin(h), r.he.Dest(h)
+	p, q := r.nodes[a], r.nodes[b]
+	if (p.x-q.x)*(p.x-q.x)+(p.y-q.y)*(p.y-q.y) < 4*r.minLen2 {
+		return false
+	}
+	v := r.addVertex((p.x+q.x)/2, (p.y+q.y)/2, []int{a, b}, []float64{0.5, 0.5})
+	r.legalize(r.splitEdge(h, v))
+	return true
+}
+
+// splitEdge splits the edge a-b of triangle abc (and of triangle bad
+// across it, if any) at vertex v, where h is the half-edge from a to b.
+// It returns the outer edges of the new triangles, which may need to be
+// legalized.
+func (r *refiner) splitEdge(h, v int) []int {
+	he := r.he
+	tri := he.Triangles
+	t := he.Twin[h]
+	a, b, c := tri[h], tri[he.Next(h)], tri[he.Prev(h)]
+	twBC, twCA := he.Twin[he.Next(h)], he.Twin[he.Prev(h)]
+	if r.isFixed(h) {
+		r.unfix(a, b)
+		r.fix(a, v)
+		r.fix(v, b)
+	}
+
+	// abc becomes avc and vbc
+	b1 := h - h%3
+	b3 := len(tri)
+	tri[b1], tri[b1+1], tri[b1+2] = a, v, c
+	he.Triangles = append(tri, v, b, c)
+	he.Twin = append(he.Twin, -1, -1, -1)
+	r.link(b1, -1)
+	r.link(b1+1, b3+2)
+	r.link(b1+2, twCA)
+	r.link(b3+1, twBC)
+	edges := []int{b1 + 2, b3 + 1}
+
+	if t >= 0 {
+		// bad becomes bvd and vad
+		tri = he.Triangles
+		d := tri[he.Prev(t)]
+		twAD, twDB := he.Twin[he.Next(t)], he.Twin[he.Prev(t)]
+		b2 := t - t%3
+		b4 := len(tri)
+		tri[b2], tri[b2+1], tri[b2+2] = b, v, d
+		he.Triangles = append(tri, v, a, d)
+		he.Twin = append(he.Twin, -1, -1, -1)
+		r.link(b2, b3)
+		r.link(b2+1, b4+2)
+		r.link(b2+2, twDB)
+		r.link(b4, b1)
+		r.link(b4+1, twAD)
+		edges = append(edges, b2+2, b4+1)
+	}
+	return edges
+}
+
+// splitTriangle splits the triangle abc starting at half-edge t into abv,
+// bcv and cav, and returns their outer edges
+func (r *refiner) splitTriangle(t, v int) []int {
+	he := r.he
+	tri := he.Triangles
+	a, b, c := tri[t], tri[t+1], tri[t+2]
+	twAB, twBC, twCA := he.Twin[t], he.Twin[t+1], he.Twin[t+2]
+	b5 := len(tri)
+	b6 := b5 + 3
+	tri[t+2] = v
+	he.Triangles = append(tri, b, c, v, c, a, v)
+	he.Twin = append(he.Twin, -1, -1, -1, -1, -1, -1)
+	r.link(t, twAB)
+	r.link(t+1, b5+2)
+	r.link(t+2, b6+1)
+	r.link(b5, twBC)
+	r.link(b5+1, b6+2)
+	r.link(b6, twCA)
+	return []int{t, b5, b6}
+}
@@ -0,0 +1,128 @@
+package earcut
+
+import (
+	"math"
+	"testing"
+)
+
+// total area of the triangles
+func trianglesArea(data []float64, dim int, tri []int) float64 {
+	var sum float64
+	for i := 0; i < len(tri); i += 3 {
+		a, b, c := tri[i]*dim, tri[i+1]*dim, tri[i+2]*dim
+		sum += math.Abs(orient2d(data[a], data[a+1], data[b], data[b+1], data[c], data[c+1]))
+	}
+	return sum / 2
+}
+
+func TestRefineMaxArea(t *testing.T) {
+	data, holeIndices := squareWithHole().flatten()
+	out, tri, err := EarcutRefine(data, holeIndices, 2, RefineOptions{MaxArea: 0.1})
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	if len(out) <= len(data) {
+		t.Fatal("Expected Steiner vertices appended to the input vertices")
+	}
+	for i, v := range data {
+		if out[i] != v {
+			t.Fatalf("Input vertex %d changed", i/2)
+		}
+	}
+	for i := 0; i < len(tri); i += 3 {
+		if a := trianglesArea(out, 2, tri[i:i+3]); a > 0.1 {
+			t.Fatalf("Triangle %d has area %f", i/3, a)
+		}
+	}
+	if a := trianglesArea(out, 2, tri); math.Abs(a-15) > 1e-9 {
+		t.Errorf("Expected area 15, got %f", a)
+	}
+	// the hole stays empty
+	for i := len(data) / 2; i < len(out)/2; i++ {
+		x, y := out[i*2], out[i*2+1]
+		if x > 1 && x < 2 && y > 1 && y < 2 {
+			t.Errorf("Steiner point (%f, %f) is inside the hole", x, y)
+		}
+	}
+}
+
+func TestRefineInterpolate(t *testing.T) {
+	// a plane, z = x + 2y, which linear interpolation keeps exactly
+	data := []float64{
+		0, 0, 0,
+		8, 0, 8,
+		8, 1, 10,
+		0, 1, 2,
+	}
+	out, tri, err := EarcutRefine(data, nil, 3, RefineOptions{MinAngle: 25, MaxArea: 0.5})
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	if len(out)%3 != 0 || len(out) == len(data) {
+		t.Fatal("Expected Steiner vertices with 3 values each")
+	}
+	for i := 0; i < len(out); i += 3 {
+		if z := out[i] + 2*out[i+1]; math.Abs(out[i+2]-z) > 1e-9 {
+			t.Errorf("Vertex %d has z %f, expected %f", i/3, out[i+2], z)
+		}
+	}
+	for i := 0; i < len(tri); i += 3 {
+		if a := minAngle(out, 3, tri[i], tri[i+1], tri[i+2]); a < 25 {
+			t.Errorf("Triangle %d has angle %f", i/3, a)
+		}
+	}
+	if a := trianglesArea(out, 3, tri); math.Abs(a-8) > 1e-9 {
+		t.Errorf("Expected area 8, got %f", a)
+	}
+}
+
+func TestRefineMaxSteiner(t *testing.T) {
+	data := []float64{0, 0, 100, 0, 100, 100, 0, 100}
+	out, _, err := EarcutRefine(data, nil, 2, RefineOptions{MaxArea: 0.01, MaxSteiner: 50})
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	if n := len(out)/2 - 4; n != 50 {
+		t.Errorf("Expected 50 Steiner points, got %d", n)
+	}
+	out, tri, _ := EarcutRefine(data, nil, 2, RefineOptions{})
+	if len(out) != len(data) || len(tri) != 6 {
+		t.Error("Expected no refinement without limits")
+	}
+}
+
+func testRefine(name string, angle float64, t *testing.T) {
+	flat, holeIndices, err := loadVertices(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(flat) / 2
+	out, tri, err := EarcutRefine(flat, holeIndices, 2, RefineOptions{MinAngle: angle})
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	exp := polygonArea(flat, holeIndices, 2)
+	if a := trianglesArea(out, 2, tri); math.Abs(a-exp) > exp*1e-9 {
+		t.Errorf("Expected area %f, got %f for %s", exp, a, name)
+	}
+	// only corners of the input may stay sharp
+	for i := 0; i < len(tri); i += 3 {
+		if minAngle(out, 2, tri[i], tri[i+1], tri[i+2]) >= angle-1e-6 {
+			continue
+		}
+		sharp := false
+		for _, v := range tri[i : i+3] {
+			sharp = sharp || v < n
+		}
+		if !sharp {
+			t.Errorf("Triangle %d has angle %f for %s", i/3, minAngle(out, 2, tri[i], tri[i+1], tri[i+2]), name)
+			return
+		}
+	}
+}
+
+func TestRefineFixtures(t *testing.T) {
+	for _, name := range []string{"building", "dude", "water3", "water3b", "hole-touching-outer", "steiner"} {
+		testRefine(name, 20, t)
+	}
+}
@@ -0,0 +1,40 @@
+package earcut
+
+// Flatten converts a polygon given as nested rings (the GeoJSON coordinate
+// layout: the outer ring first, followed by any holes) into the flat vertex
+// array and hole index list expected by Earcut.
+//
+// Rings may be closed (first point repeated at the end) or open; points are
+// copied as given, since Earcut already ignores a repeated closing point.
+// Empty holes are skipped so that every returned hole index points at a real
+// vertex.
+func Flatten(rings [][][2]float64) ([]float64, []int) {
+	flat := []float64{}
+	holeIndices := []int{}
+	j := 0
+	for i, ring := range rings {
+		if i > 0 {
+			if len(ring) == 0 {
+				continue
+			}
+			holeIndices = append(holeIndices, j)
+		}
+		for _, pt := range ring {
+			flat = append(flat, pt[0], pt[1])
+			j++
+		}
+	}
+	return flat, holeIndices
+}
+
+// EarcutRings triangulates a polygon given as nested rings.  It returns the
+// triangle vertex indices along with the flattened vertex array they refer
+// to (2 values per vertex).
+func EarcutRings(rings [][][2]float64) ([]int, []float64, error) {
+	data, holeIndices := Flatten(rings)
+	triangles, err := Earcut(data, holeIndices, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return triangles, data, nil
+}
@@ -0,0 +1,81 @@
+package earcut
+
+import (
+	"testing"
+)
+
+func TestFlattenClosedAndOpen(t *testing.T) {
+	closed := [][][2]float64{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+	}
+	open := [][][2]float64{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}},
+	}
+	flat, holes := Flatten(closed)
+	if len(flat) != 20 {
+		t.Errorf("Expected 20 values, got %d", len(flat))
+	}
+	if !checkVerts([]int{5}, holes) {
+		t.Error("Hole indices don't match", holes)
+	}
+	flat, holes = Flatten(open)
+	if len(flat) != 16 {
+		t.Errorf("Expected 16 values, got %d", len(flat))
+	}
+	if !checkVerts([]int{4}, holes) {
+		t.Error("Hole indices don't match", holes)
+	}
+	for _, rings := range [][][][2]float64{closed, open} {
+		tri, data, err := EarcutRings(rings)
+		if err != nil {
+			t.Error("Error in EarcutRings:", err)
+		}
+		if len(tri) != 24 {
+			t.Errorf("Expected 24 vertex indices, got %d", len(tri))
+		}
+		_, holes = Flatten(rings)
+		if d := Deviation(data, holes, 2, tri); d > epsilon {
+			t.Errorf(
+				"Triangle area not equal to polygon area (%.6f%% deviation",
+				d*100.0)
+		}
+	}
+}
+
+func TestFlattenSkipsEmptyHoles(t *testing.T) {
+	rings := [][][2]float64{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+		{},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}},
+	}
+	_, holes := Flatten(rings)
+	if !checkVerts([]int{4}, holes) {
+		t.Error("Hole indices don't match", holes)
+	}
+}
+
+func TestEarcutRings(t *testing.T) {
+	rings := [][][2]float64{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+	}
+	tri, data, err := EarcutRings(rings)
+	if err != nil {
+		t.Error("Error in EarcutRings:", err)
+	}
+	if len(tri) != 24 {
+		t.Errorf("Expected 24 vertex indices, got %d", len(tri))
+	}
+	for _, i := range tri {
+		if i < 0 || i*2 >= len(data) {
+			t.Errorf("Index %d out of range", i)
+		}
+	}
+	if d := Deviation(data, []int{5}, 2, tri); d > epsilon {
+		t.Errorf(
+			"Triangle area not equal to polygon area (%.6f%% deviation",
+			d*100.0)
+	}
+}
@@ -0,0 +1,408 @@
+// Package shapefile reads polygons from ESRI shapefiles (.shp with an
+// optional .shx index) and triangulates them with earcut.
+package shapefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// ShapeType is the type code of a shapefile or of a single record.
+type ShapeType int32
+
+// Supported shape types
+const (
+	Null     ShapeType = 0
+	Polygon  ShapeType = 5
+	PolygonZ ShapeType = 15
+	PolygonM ShapeType = 25
+)
+
+const (
+	fileCode   = 9994
+	version    = 1000
+	headerSize = 100
+)
+
+// Header is the header shared by .shp and .shx files.
+type Header struct {
+	ShapeType ShapeType
+
+	// Length is the file length in bytes.
+	Length int64
+
+	// Min and Max are the bounds of all records, in x, y, z, m order.
+	Min [4]float64
+	Max [4]float64
+}
+
+func readHeader(r io.Reader) (Header, error) {
+	var b [headerSize]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return Header{}, fmt.Errorf("shapefile: reading header: %s", err)
+	}
+	if code := binary.BigEndian.Uint32(b[0:]); code != fileCode {
+		return Header{}, fmt.Errorf("shapefile: bad file code %d", code)
+	}
+	if v := binary.LittleEndian.Uint32(b[28:]); v != version {
+		return Header{}, fmt.Errorf("shapefile: unsupported version %d", v)
+	}
+	h := Header{
+		ShapeType: ShapeType(binary.LittleEndian.Uint32(b[32:])),
+		Length:    int64(binary.BigEndian.Uint32(b[24:])) * 2,
+	}
+	float := func(off int) float64 {
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[off:]))
+	}
+	h.Min = [4]float64{float(36), float(44), float(68), float(84)}
+	h.Max = [4]float64{float(52), float(60), float(76), float(92)}
+	return h, nil
+}
+
+// Record is a single polygon record.  Its Data is reordered so that each
+// polygon's outer ring is followed by its holes.
+type Record struct {
+	// Number is the 1-based record number.
+	Number int
+	Type   ShapeType
+
+	earcut.Geometry
+}
+
+// Reader reads records sequentially from a .shp file.
+type Reader struct {
+	r      io.Reader
+	header Header
+	pos    int64
+}
+
+// NewReader reads the .shp header from r, and returns a Reader positioned
+// at the first record.
+func NewReader(r io.Reader) (*Reader, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	switch h.ShapeType {
+	case Null, Polygon, PolygonZ, PolygonM:
+	default:
+		return nil, fmt.Errorf("shapefile: unsupported shape type %d", h.ShapeType)
+	}
+	return &Reader{r: r, header: h, pos: headerSize}, nil
+}
+
+// Header returns the .shp file header.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next reads the next record.  It returns io.EOF when there are no more
+// records.
+func (r *Reader) Next() (*Record, error) {
+	if r.pos >= r.header.Length {
+		return nil, io.EOF
+	}
+	var b [8]byte
+	if _, err := io.ReadFull(r.r, b[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("shapefile: reading record header at offset %d: %s", r.pos, err)
+	}
+	number := int(binary.BigEndian.Uint32(b[0:]))
+	length := int64(binary.BigEndian.Uint32(b[4:])) * 2
+	if length > r.header.Length-r.pos-8 {
+		return nil, fmt.Errorf("shapefile: record %d length %d runs past the end of the file", number, length)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r.r, content); err != nil {
+		return nil, fmt.Errorf("shapefile: reading record %d: %s", number, err)
+	}
+	r.pos += int64(8 + len(content))
+	return parseRecord(number, content)
+}
+
+// IndexEntry locates a record in a .shp file.
+type IndexEntry struct {
+	// Offset is the byte offset of the record header.
+	Offset int64
+
+	// Length is the length of the record content in bytes, not
+	// counting the record header.
+	Length int64
+}
+
+// ReadIndex reads the entries of a .shx file.
+func ReadIndex(r io.Reader) ([]IndexEntry, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	n := (h.Length - headerSize) / 8
+	if n < 0 {
+		return nil, errors.New("shapefile: bad index length")
+	}
+	entries := make([]IndexEntry, 0, n)
+	var b [8]byte
+	for i := int64(0); i < n; i++ {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, fmt.Errorf("shapefile: reading index entry %d: %s", i, err)
+		}
+		entries = append(entries, IndexEntry{
+			Offset: int64(binary.BigEndian.Uint32(b[0:])) * 2,
+			Length: int64(binary.BigEndian.Uint32(b[4:])) * 2,
+		})
+	}
+	return entries, nil
+}
+
+// ReadRecordAt reads the record located by an index entry.  If r has a
+// Size or Stat method, as *bytes.Reader and *os.File do, entries that run
+// past the end of the file are rejected before anything is read.
+func ReadRecordAt(r io.ReaderAt, e IndexEntry) (*Record, error) {
+	if e.Offset < 0 || e.Length < 0 {
+		return nil, fmt.Errorf("shapefile: bad index entry at offset %d", e.Offset)
+	}
+	if size, ok := readerSize(r); ok && e.Length > size-e.Offset-8 {
+		return nil, fmt.Errorf("shapefile: record at offset %d length %d runs past the end of the file", e.Offset, e.Length)
+	}
+	b := make([]byte, 8+e.Length)
+	if _, err := r.ReadAt(b, e.Offset); err != nil {
+		return nil, fmt.Errorf("shapefile: reading record at offset %d: %s", e.Offset, err)
+	}
+	number := int(binary.BigEndian.Uint32(b[0:]))
+	if l := int64(binary.BigEndian.Uint32(b[4:])) * 2; l != e.Length {
+		return nil, fmt.Errorf("shapefile: record %d length %d doesn't match index", number, l)
+	}
+	return parseRecord(number, b[8:])
+}
+
+// readerSize returns the size of the file behind r, if r can report it
+func readerSize(r io.ReaderAt) (int64, bool) {
+	switch f := r.(type) {
+	case interface{ Size() int64 }:
+		return f.Size(), true
+	case interface{ Stat() (os.FileInfo, error) }:
+		if fi, err := f.Stat(); err == nil {
+			return fi.Size(), true
+		}
+	}
+	return 0, false
+}
+
+// File is an open .shp file and its .shx index, for random access to
+// records.
+type File struct {
+	Header Header
+	Index  []IndexEntry
+	shp    *os.File
+}
+
+// Open opens a .shp file along with the .shx file next to it.
+func Open(name string) (*File, error) {
+	base := strings.TrimSuffix(name, ".shp")
+	shx, err := os.Open(base + ".shx")
+	if err != nil {
+		return nil, err
+	}
+	defer shx.Close()
+	index, err := ReadIndex(shx)
+	if err != nil {
+		return nil, err
+	}
+	shp, err := os.Open(base + ".shp")
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(shp)
+	if err != nil {
+		shp.Close()
+		return nil, err
+	}
+	return &File{Header: r.Header(), Index: index, shp: shp}, nil
+}
+
+// NumRecords returns the number of records in the file.
+func (f *File) NumRecords() int {
+	return len(f.Index)
+}
+
+// Record reads record i, counting from 0.
+func (f *File) Record(i int) (*Record, error) {
+	if i < 0 || i >= len(f.Index) {
+		return nil, fmt.Errorf("shapefile: record %d out of range", i)
+	}
+	return ReadRecordAt(f.shp, f.Index[i])
+}
+
+// Close closes the underlying .shp file.
+func (f *File) Close() error {
+	return f.shp.Close()
+}
+
+// decode the content of a record
+func parseRecord(number int, b []byte) (*Record, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("shapefile: record %d truncated", number)
+	}
+	rec := &Record{
+		Number: number,
+		Type:   ShapeType(binary.LittleEndian.Uint32(b)),
+		Geometry: earcut.Geometry{
+			Dim:      2,
+			Data:     []float64{},
+			Polygons: []earcut.Part{},
+		},
+	}
+	switch rec.Type {
+	case Null:
+		return rec, nil
+	case Polygon:
+	case PolygonZ:
+		rec.HasZ = true
+		rec.Dim = 3
+	case PolygonM:
+		rec.HasM = true
+		rec.Dim = 3
+	default:
+		return nil, fmt.Errorf("shapefile: record %d has unsupported shape type %d", number, rec.Type)
+	}
+
+	// type, bounding box, part and point counts
+	if len(b) < 44 {
+		return nil, fmt.Errorf("shapefile: record %d truncated", number)
+	}
+	numParts := int(binary.LittleEndian.Uint32(b[36:]))
+	numPoints := int(binary.LittleEndian.Uint32(b[40:]))
+	xyEnd := 44 + numParts*4 + numPoints*16
+	if numParts < 0 || numPoints < 0 || xyEnd > len(b) || xyEnd < 44 {
+		return nil, fmt.Errorf("shapefile: record %d truncated", number)
+	}
+	parts := make([]int, numParts+1)
+	for i := 0; i < numParts; i++ {
+		parts[i] = int(binary.LittleEndian.Uint32(b[44+i*4:]))
+		if parts[i] < 0 || parts[i] > numPoints || (i > 0 && parts[i] < parts[i-1]) {
+			return nil, fmt.Errorf("shapefile: record %d has invalid part index %d", number, parts[i])
+		}
+	}
+	parts[numParts] = numPoints
+	float := func(off int) float64 {
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[off:]))
+	}
+
+	// Z values (in PolygonZ) or M values (in PolygonM) follow the x, y
+	// values, each preceded by their range; PolygonZ may be followed by
+	// optional M values as well
+	extraEnd := xyEnd + 16 + numPoints*8
+	if rec.Type != Polygon && extraEnd > len(b) {
+		return nil, fmt.Errorf("shapefile: record %d truncated", number)
+	}
+	if rec.Type == PolygonZ && extraEnd+16+numPoints*8 <= len(b) {
+		rec.HasM = true
+		rec.Dim = 4
+	}
+	data := make([]float64, numPoints*rec.Dim)
+	for i := 0; i < numPoints; i++ {
+		data[i*rec.Dim] = float(44 + numParts*4 + i*16)
+		data[i*rec.Dim+1] = float(44 + numParts*4 + i*16 + 8)
+		if rec.Dim > 2 {
+			data[i*rec.Dim+2] = float(xyEnd + 16 + i*8)
+		}
+		if rec.Dim > 3 {
+			data[i*rec.Dim+3] = float(extraEnd + 16 + i*8)
+		}
+	}
+	rec.group(data, parts)
+	return rec, nil
+}
+
+type ring struct {
+	start int
+	end   int
+	area  float64
+}
+
+// signed area of a ring; negative for clockwise rings
+func signedArea(data []float64, start, end, dim int) float64 {
+	var sum float64
+	for i, j := start, end-dim; i < end; i += dim {
+		sum += (data[j] - data[i]) * (data[i+1] + data[j+1])
+		j = i
+	}
+	return sum
+}
+
+// check whether a point is inside a ring, using the even-odd rule
+func inside(data []float64, r ring, dim int, px, py float64) bool {
+	in := false
+	for i, j := r.start, r.end-dim; i < r.end; i += dim {
+		if (data[i+1] > py) != (data[j+1] > py) &&
+			px < (data[j]-data[i])*(py-data[i+1])/(data[j+1]-data[i+1])+data[i] {
+			in = !in
+		}
+		j = i
+	}
+	return in
+}
+
+// group the parts of a record into polygons; clockwise parts are outer
+// rings, and counter-clockwise parts are holes of the smallest outer ring
+// containing them, or outer rings themselves if no outer ring does
+func (rec *Record) group(data []float64, parts []int) {
+	dim := rec.Dim
+	outers := []ring{}
+	holes := []ring{}
+	for i := 0; i+1 < len(parts); i++ {
+		r := ring{start: parts[i] * dim, end: parts[i+1] * dim}
+		if r.start == r.end {
+			continue
+		}
+		r.area = signedArea(data, r.start, r.end, dim)
+		if r.area < 0 {
+			outers = append(outers, r)
+		} else if r.area > 0 {
+			holes = append(holes, r)
+		}
+	}
+	owned := make([][]ring, len(outers))
+	for _, h := range holes {
+		best := -1
+		for i, o := range outers {
+			if inside(data, o, dim, data[h.start], data[h.start+1]) &&
+				(best < 0 || math.Abs(o.area) < math.Abs(outers[best].area)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			outers = append(outers, h)
+			owned = append(owned, nil)
+		} else {
+			owned[best] = append(owned[best], h)
+		}
+	}
+	order := make([]int, len(outers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return outers[order[i]].start < outers[order[j]].start
+	})
+	for _, i := range order {
+		part := earcut.Part{Start: len(rec.Data) / dim}
+		rec.Data = append(rec.Data, data[outers[i].start:outers[i].end]...)
+		for _, h := range owned[i] {
+			part.Holes = append(part.Holes, len(rec.Data)/dim)
+			rec.Data = append(rec.Data, data[h.start:h.end]...)
+		}
+		part.End = len(rec.Data) / dim
+		rec.Polygons = append(rec.Polygons, part)
+	}
+}
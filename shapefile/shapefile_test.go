@@ -0,0 +1,280 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+var epsilon = float64(1.0e-12)
+
+// build the content of a polygon record; each part is a flat list of x, y
+// values, and z and m (if not nil) hold one value per point
+func polygonContent(typ ShapeType, parts [][]float64, z, m []float64) []byte {
+	buf := &bytes.Buffer{}
+	put := func(v interface{}) {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	numPoints := 0
+	for _, part := range parts {
+		numPoints += len(part) / 2
+	}
+	put(int32(typ))
+	put([4]float64{})
+	put(int32(len(parts)))
+	put(int32(numPoints))
+	start := 0
+	for _, part := range parts {
+		put(int32(start))
+		start += len(part) / 2
+	}
+	for _, part := range parts {
+		put(part)
+	}
+	if z != nil {
+		put([2]float64{})
+		put(z)
+	}
+	if m != nil {
+		put([2]float64{})
+		put(m)
+	}
+	return buf.Bytes()
+}
+
+// build .shp and .shx files from record contents
+func buildFiles(typ ShapeType, records ...[]byte) ([]byte, []byte) {
+	header := func(length int) []byte {
+		b := make([]byte, headerSize)
+		binary.BigEndian.PutUint32(b[0:], fileCode)
+		binary.BigEndian.PutUint32(b[24:], uint32(length/2))
+		binary.LittleEndian.PutUint32(b[28:], version)
+		binary.LittleEndian.PutUint32(b[32:], uint32(typ))
+		binary.LittleEndian.PutUint64(b[36:], math.Float64bits(-1))
+		binary.LittleEndian.PutUint64(b[60:], math.Float64bits(20))
+		return b
+	}
+	shp := []byte{}
+	shx := []byte{}
+	offset := headerSize
+	for i, content := range records {
+		var rh [8]byte
+		binary.BigEndian.PutUint32(rh[0:], uint32(i+1))
+		binary.BigEndian.PutUint32(rh[4:], uint32(len(content)/2))
+		shp = append(shp, rh[:]...)
+		shp = append(shp, content...)
+		var ie [8]byte
+		binary.BigEndian.PutUint32(ie[0:], uint32(offset/2))
+		binary.BigEndian.PutUint32(ie[4:], uint32(len(content)/2))
+		shx = append(shx, ie[:]...)
+		offset += 8 + len(content)
+	}
+	shp = append(header(headerSize+len(shp)), shp...)
+	shx = append(header(headerSize+len(shx)), shx...)
+	return shp, shx
+}
+
+var (
+	// clockwise outer rings and a counter-clockwise hole
+	outerA = []float64{0, 0, 0, 10, 10, 10, 10, 0, 0, 0}
+	holeA  = []float64{2, 2, 4, 2, 4, 4, 2, 4, 2, 2}
+	outerB = []float64{20, 0, 20, 5, 25, 5, 25, 0, 20, 0}
+)
+
+func TestReadPolygon(t *testing.T) {
+	shp, _ := buildFiles(Polygon,
+		polygonContent(Polygon, [][]float64{holeA, outerB, outerA}, nil, nil),
+		[]byte{0, 0, 0, 0},
+	)
+	r, err := NewReader(bytes.NewReader(shp))
+	if err != nil {
+		t.Fatal("Error reading header:", err)
+	}
+	h := r.Header()
+	if h.ShapeType != Polygon || h.Length != int64(len(shp)) || h.Min[0] != -1 || h.Max[1] != 20 {
+		t.Errorf("Header doesn't match: %+v", h)
+	}
+	rec, err := r.Next()
+	if err != nil {
+		t.Fatal("Error reading record:", err)
+	}
+	if rec.Number != 1 || rec.Dim != 2 || rec.HasZ || rec.HasM {
+		t.Errorf("Record doesn't match: %+v", rec)
+	}
+	if len(rec.Polygons) != 2 {
+		t.Fatalf("Expected 2 polygons, got %d", len(rec.Polygons))
+	}
+	// outerB comes first in the file, and the hole belongs to outerA
+	if p := rec.Polygons[0]; p.Start != 0 || p.End != 5 || len(p.Holes) != 0 {
+		t.Error("First polygon doesn't match", p)
+	}
+	if p := rec.Polygons[1]; p.Start != 5 || p.End != 15 || len(p.Holes) != 1 || p.Holes[0] != 10 {
+		t.Error("Second polygon doesn't match", p)
+	}
+	if rec.Data[10] != 0 || rec.Data[20] != 2 {
+		t.Error("Vertex data not grouped by polygon", rec.Data)
+	}
+	tri, ranges, err := rec.Triangulate()
+	if err != nil {
+		t.Fatal("Error triangulating record:", err)
+	}
+	r2 := ranges[1]
+	local := []int{}
+	for _, v := range tri[r2.Start:r2.End] {
+		local = append(local, v-5)
+	}
+	if d := earcut.Deviation(rec.Data[10:], []int{5}, 2, local); d > epsilon {
+		t.Errorf("Triangle area not equal to polygon area (%.6f%% deviation", d*100.0)
+	}
+
+	rec, err = r.Next()
+	if err != nil {
+		t.Fatal("Error reading null record:", err)
+	}
+	if rec.Type != Null || len(rec.Polygons) != 0 {
+		t.Errorf("Expected null record, got %+v", rec)
+	}
+	if _, err = r.Next(); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
+
+func TestReadPolygonZM(t *testing.T) {
+	z := []float64{1, 2, 3, 4, 5}
+	m := []float64{6, 7, 8, 9, 10}
+	shp, shx := buildFiles(PolygonZ,
+		polygonContent(PolygonZ, [][]float64{outerA}, z, nil),
+		polygonContent(PolygonZ, [][]float64{outerA}, z, m),
+		polygonContent(PolygonM, [][]float64{outerA}, nil, m),
+	)
+	index, err := ReadIndex(bytes.NewReader(shx))
+	if err != nil {
+		t.Fatal("Error reading index:", err)
+	}
+	if len(index) != 3 {
+		t.Fatalf("Expected 3 index entries, got %d", len(index))
+	}
+	tests := []struct {
+		hasZ  bool
+		hasM  bool
+		dim   int
+		extra []float64
+	}{
+		{true, false, 3, z},
+		{true, true, 4, m},
+		{false, true, 3, m},
+	}
+	for i, test := range tests {
+		rec, err := ReadRecordAt(bytes.NewReader(shp), index[i])
+		if err != nil {
+			t.Errorf("Error reading record %d: %s", i, err)
+			continue
+		}
+		if rec.Number != i+1 || rec.HasZ != test.hasZ || rec.HasM != test.hasM || rec.Dim != test.dim {
+			t.Errorf("Record %d doesn't match: %+v", i, rec)
+			continue
+		}
+		for j, v := range test.extra {
+			if rec.Data[j*rec.Dim+rec.Dim-1] != v {
+				t.Errorf("Record %d extra values don't match: %v", i, rec.Data)
+				break
+			}
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shapefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	shp, shx := buildFiles(Polygon,
+		polygonContent(Polygon, [][]float64{outerA, holeA}, nil, nil),
+		polygonContent(Polygon, [][]float64{outerB}, nil, nil),
+	)
+	name := filepath.Join(dir, "test.shp")
+	if err = ioutil.WriteFile(name, shp, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "test.shx"), shx, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Open(name)
+	if err != nil {
+		t.Fatal("Error opening shapefile:", err)
+	}
+	defer f.Close()
+	if f.NumRecords() != 2 {
+		t.Fatalf("Expected 2 records, got %d", f.NumRecords())
+	}
+	rec, err := f.Record(1)
+	if err != nil {
+		t.Fatal("Error reading record:", err)
+	}
+	if rec.Number != 2 || len(rec.Data) != 10 {
+		t.Errorf("Record doesn't match: %+v", rec)
+	}
+	rec, err = f.Record(0)
+	if err != nil {
+		t.Fatal("Error reading record:", err)
+	}
+	tri, _, err := rec.Triangulate()
+	if err != nil {
+		t.Fatal("Error triangulating record:", err)
+	}
+	if d := earcut.Deviation(rec.Data, []int{5}, 2, tri); d > epsilon {
+		t.Errorf("Triangle area not equal to polygon area (%.6f%% deviation", d*100.0)
+	}
+	if _, err = f.Record(2); err == nil {
+		t.Error("Expected error for out of range record")
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	content := polygonContent(PolygonZ, [][]float64{outerA}, []float64{1, 2, 3, 4, 5}, nil)
+	for _, n := range []int{2, 40, 60, len(content) - 1} {
+		if _, err := parseRecord(1, content[:n]); err == nil {
+			t.Errorf("Expected error for record truncated to %d bytes", n)
+		}
+	}
+	point := []byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	if _, err := parseRecord(1, point); err == nil {
+		t.Error("Expected error for point record")
+	}
+	shp, _ := buildFiles(Polygon)
+	shp[0] = 1
+	if _, err := NewReader(bytes.NewReader(shp)); err == nil {
+		t.Error("Expected error for bad file code")
+	}
+	shp, _ = buildFiles(1)
+	if _, err := NewReader(bytes.NewReader(shp)); err == nil {
+		t.Error("Expected error for point shapefile")
+	}
+
+	// a corrupt record length mustn't be trusted for allocation
+	shp, shx := buildFiles(Polygon, polygonContent(Polygon, [][]float64{outerA}, nil, nil))
+	binary.BigEndian.PutUint32(shp[headerSize+4:], 0x7fffffff)
+	r, err := NewReader(bytes.NewReader(shp))
+	if err != nil {
+		t.Fatal("Error reading header:", err)
+	}
+	if _, err = r.Next(); err == nil || err == io.EOF {
+		t.Errorf("Expected error for record running past the end, got %v", err)
+	}
+	index, err := ReadIndex(bytes.NewReader(shx))
+	if err != nil {
+		t.Fatal("Error reading index:", err)
+	}
+	index[0].Length = 0xfffffffe
+	if _, err = ReadRecordAt(bytes.NewReader(shp), index[0]); err == nil {
+		t.Error("Expected error for index entry running past the end")
+	}
+}
@@ -0,0 +1,111 @@
+package earcut
+
+import (
+	"fmt"
+	"math"
+)
+
+// SteinerPointError is returned by EarcutSteiner for a point that can't be
+// used.
+type SteinerPointError struct {
+	// Index is the index of the point in the points passed in.
+	Index int
+	Msg   string
+}
+
+func (e *SteinerPointError) Error() string {
+	return fmt.Sprintf("steiner point %d %s", e.Index, e.Msg)
+}
+
+// EarcutSteiner triangulates a polygon that must also have vertices at
+// the given interior points, such as elevation samples inside a lake.
+//
+// The data, holeIndices and dim are as for Earcut, and points holds the
+// extra vertices with dim values each.  EarcutSteiner returns the triangle
+// indices along with the vertex array they refer to, which is data
+// followed by points, so Steiner point i is vertex len(data)/dim + i.
+//
+// Every point must lie strictly inside the polygon, and no two points may
+// be the same; otherwise a *SteinerPointError is returned.  Points on the
+// outer ring or a hole are rejected too, as they would split a ring edge
+// rather than become interior vertices.
+//
+// Every point is a vertex of at least one of the triangles returned.  If
+// the ear slicing loop gives up on part of a badly formed polygon and a
+// point is left out, a *SteinerPointError is returned for it instead.
+//
+// This is a wrapper for the way Earcut treats a hole with a single vertex:
+// as a point to join up to the rest of the polygon rather than something
+// to cut out.
+func EarcutSteiner(data []float64, holeIndices []int, dim int, points []float64) ([]int, []float64, error) {
+	if dim < 2 {
+		return nil, nil, fmt.Errorf("need at least 2 dimensions")
+	}
+	if len(points)%dim != 0 {
+		return nil, nil, fmt.Errorf("steiner point array length %d is not a multiple of %d", len(points), dim)
+	}
+	if err := validateSteiner(data, holeIndices, dim, points); err != nil {
+		return nil, nil, err
+	}
+	n := len(data) / dim
+	all := make([]float64, 0, n*dim+len(points))
+	all = append(all, data[:n*dim]...)
+	all = append(all, points...)
+	holes := make([]int, 0, len(holeIndices)+len(points)/dim)
+	holes = append(holes, holeIndices...)
+	for i := 0; i < len(points)/dim; i++ {
+		holes = append(holes, n+i)
+	}
+	triangles, err := Earcut(all, holes, dim)
+	if err != nil {
+		return nil, nil, err
+	}
+	used := make([]bool, len(points)/dim)
+	for _, v := range triangles {
+		if v >= n {
+			used[v-n] = true
+		}
+	}
+	for i, u := range used {
+		if !u {
+			return nil, nil, &SteinerPointError{Index: i, Msg: "was left out of the triangulation"}
+		}
+	}
+	return triangles, all, nil
+}
+
+// check that every point is inside the polygon, off its rings, and unique
+func validateSteiner(data []float64, holeIndices []int, dim int, points []float64) error {
+	seen := map[[2]float64]int{}
+	for i := 0; i < len(points)/dim; i++ {
+		x, y := points[i*dim], points[i*dim+1]
+		if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+			return &SteinerPointError{Index: i, Msg: "is not a finite point"}
+		}
+		if j, ok := seen[[2]float64{x, y}]; ok {
+			return &SteinerPointError{Index: i, Msg: fmt.Sprintf("is the same as point %d", j)}
+		}
+		seen[[2]float64{x, y}] = i
+		if onRings(data, holeIndices, dim, x, y) {
+			return &SteinerPointError{Index: i, Msg: "is on the polygon boundary"}
+		}
+		if !polygonContains(data, holeIndices, dim, x, y) {
+			return &SteinerPointError{Index: i, Msg: "is outside the polygon"}
+		}
+	}
+	return nil
+}
+
+// whether a point lies on any ring segment, within rounding error
+func onRings(data []float64, holeIndices []int, dim int, x, y float64) bool {
+	for i, l := 0, len(holeIndices); i <= l; i++ {
+		start, end := ringRange(data, holeIndices, dim, i)
+		for j, k := start, end-dim; j < end; j += dim {
+			if onSegment(data[k], data[k+1], data[j], data[j+1], x, y) {
+				return true
+			}
+			k = j
+		}
+	}
+	return false
+}
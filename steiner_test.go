@@ -0,0 +1,97 @@
+package earcut
+
+import (
+	"testing"
+)
+
+func TestEarcutSteiner(t *testing.T) {
+	data := []float64{0, 0, 100, 0, 100, 100, 0, 100}
+	points := []float64{50, 52, 30, 40, 70, 60, 20, 70}
+	tri, all, err := EarcutSteiner(data, nil, 2, points)
+	if err != nil {
+		t.Fatal("Error making triangles with steiner points:", err)
+	}
+	if len(tri) != 30 {
+		t.Errorf("Expected 30 vertex indices, got %d", len(tri))
+	}
+	if len(all) != len(data)+len(points) {
+		t.Errorf("Expected %d values, got %d", len(data)+len(points), len(all))
+	}
+	used := map[int]bool{}
+	for _, v := range tri {
+		used[v] = true
+	}
+	for i := 4; i < 8; i++ {
+		if !used[i] {
+			t.Errorf("Steiner point %d isn't used", i-4)
+		}
+	}
+	// single vertex holes have no area
+	if d := Deviation(all, []int{4, 5, 6, 7}, 2, tri); d > epsilon {
+		t.Errorf(
+			"Triangle area not equal to polygon area (%.6f%% deviation",
+			d*100.0)
+	}
+}
+
+func TestEarcutSteinerWithHole(t *testing.T) {
+	// elevation samples in a lake with an island
+	data := []float64{
+		0, 0, 5, 10, 0, 5, 10, 10, 5, 0, 10, 5,
+		4, 4, 5, 6, 4, 5, 6, 6, 5, 4, 6, 5,
+	}
+	points := []float64{2, 1, -3, 8, 7, -7, 2, 8, -1}
+	tri, all, err := EarcutSteiner(data, []int{4}, 3, points)
+	if err != nil {
+		t.Fatal("Error making triangles with steiner points:", err)
+	}
+	if len(tri) != 42 {
+		t.Errorf("Expected 42 vertex indices, got %d", len(tri))
+	}
+	if all[8*3+2] != -3 || all[10*3+2] != -1 {
+		t.Error("Steiner point values weren't kept")
+	}
+	if d := Deviation(all, []int{4, 8, 9, 10}, 3, tri); d > epsilon {
+		t.Errorf(
+			"Triangle area not equal to polygon area (%.6f%% deviation",
+			d*100.0)
+	}
+}
+
+func TestEarcutSteinerInvalid(t *testing.T) {
+	data := []float64{0, 0, 10, 0, 10, 10, 0, 10, 4, 4, 6, 4, 6, 6, 4, 6}
+	holes := []int{4}
+	for _, tc := range []struct {
+		points []float64
+		index  int
+	}{
+		{[]float64{1, 1, 11, 5}, 1},
+		{[]float64{5, 5}, 0},
+		{[]float64{1, 1, 5, 0}, 1},
+		{[]float64{10, 10}, 0},
+		{[]float64{6, 5}, 0},
+		{[]float64{1, 1, 2, 2, 1, 1}, 2},
+	} {
+		_, _, err := EarcutSteiner(data, holes, 2, tc.points)
+		serr, ok := err.(*SteinerPointError)
+		if !ok {
+			t.Errorf("Expected a SteinerPointError for %v, got %v", tc.points, err)
+			continue
+		}
+		if serr.Index != tc.index {
+			t.Errorf("Expected error for point %d of %v, got %d (%s)", tc.index, tc.points, serr.Index, serr)
+		}
+	}
+	if _, _, err := EarcutSteiner(data, holes, 2, []float64{1, 1, 2}); err == nil {
+		t.Error("Expected error for partial point")
+	}
+}
+
+func TestEarcutSteinerLeftOut(t *testing.T) {
+	// the ear slicing loop gives up on a bow tie, leaving the point out
+	bowtie := []float64{0, 0, 10, 10, 10, 0, 0, 10}
+	_, _, err := EarcutSteiner(bowtie, nil, 2, []float64{2, 5})
+	if serr, ok := err.(*SteinerPointError); !ok || serr.Index != 0 {
+		t.Errorf("Expected a SteinerPointError for point 0, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package earcut
+
+// TriangleStrip is a triangulation converted to a single triangle strip.
+// Separate strips are joined with degenerate triangles, and padded so that
+// every strip starts on an even triangle, keeping the original winding.
+type TriangleStrip struct {
+	// Indices is the joined strip.  Triangle k is made of indices k,
+	// k+1 and k+2, with the first two swapped when k is odd.
+	Indices []int
+
+	// Strips is the number of strips that were joined.
+	Strips int
+
+	// Triangles is the number of non-degenerate triangles in the strip.
+	Triangles int
+}
+
+// Stripify converts a triangle list, as returned by Earcut, into a triangle
+// strip.  Strips are grown greedily across shared edges, trying each edge
+// of each starting triangle and keeping the longest strip.
+func Stripify(triangles []int) *TriangleStrip {
+	he := NewHalfEdges(triangles)
+	n := len(triangles) / 3
+	used := make([]bool, n)
+	ts := &TriangleStrip{Indices: []int{}}
+
+	// walk a strip from half-edge start without marking triangles used,
+	// returning the half-edges it enters each triangle through
+	walk := func(start int, mark []int) []int {
+		entries := []int{start}
+		mark[start/3] = start + 1
+		h := start
+		for k := 1; ; k++ {
+			var exit int
+			if k%2 == 1 {
+				exit = he.Next(h)
+			} else {
+				exit = he.Prev(h)
+			}
+			g := he.Twin[exit]
+			if g < 0 || used[g/3] || mark[g/3] == start+1 {
+				break
+			}
+			mark[g/3] = start + 1
+			entries = append(entries, g)
+			h = g
+		}
+		return entries
+	}
+
+	mark := make([]int, n)
+	for t := 0; t < n; t++ {
+		if used[t] {
+			continue
+		}
+		var best []int
+		for e := 0; e < 3; e++ {
+			if entries := walk(t*3+e, mark); len(entries) > len(best) {
+				best = entries
+			}
+		}
+		for _, h := range best {
+			used[h/3] = true
+		}
+
+		// the first triangle contributes all three of its vertices, and
+		// each later one the vertex opposite the edge it was entered by
+		h := best[0]
+		strip := []int{he.Origin(h), he.Dest(h), he.Dest(he.Next(h))}
+		for _, g := range best[1:] {
+			strip = append(strip, he.Dest(he.Next(g)))
+		}
+		ts.join(strip)
+		ts.Strips++
+		ts.Triangles += len(best)
+	}
+	return ts
+}
+
+// append a strip, joining it to the previous one with degenerate triangles
+func (ts *TriangleStrip) join(strip []int) {
+	if len(ts.Indices) > 0 {
+		ts.Indices = append(ts.Indices, ts.Indices[len(ts.Indices)-1], strip[0])
+		if len(ts.Indices)%2 == 1 {
+			ts.Indices = append(ts.Indices, strip[0])
+		}
+	}
+	ts.Indices = append(ts.Indices, strip...)
+}
+
+// TriangleList converts the strip back into a triangle list, dropping the
+// degenerate triangles.
+func (ts *TriangleStrip) TriangleList() []int {
+	triangles := make([]int, 0, ts.Triangles*3)
+	for k := 0; k+2 < len(ts.Indices); k++ {
+		a, b, c := ts.Indices[k], ts.Indices[k+1], ts.Indices[k+2]
+		if a == b || b == c || a == c {
+			continue
+		}
+		if k%2 == 1 {
+			a, b = b, a
+		}
+		triangles = append(triangles, a, b, c)
+	}
+	return triangles
+}
@@ -0,0 +1,65 @@
+package earcut
+
+import (
+	"sort"
+	"testing"
+)
+
+// normalize triangles to start at their smallest index, keeping winding,
+// and sort them so triangle lists can be compared
+func normalizeTriangles(triangles []int) [][3]int {
+	out := make([][3]int, 0, len(triangles)/3)
+	for i := 0; i+2 < len(triangles); i += 3 {
+		a, b, c := triangles[i], triangles[i+1], triangles[i+2]
+		for a > b || a > c {
+			a, b, c = b, c, a
+		}
+		out = append(out, [3]int{a, b, c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		for k := 0; k < 3; k++ {
+			if out[i][k] != out[j][k] {
+				return out[i][k] < out[j][k]
+			}
+		}
+		return false
+	})
+	return out
+}
+
+func TestStripifySquare(t *testing.T) {
+	tri := []int{0, 1, 2, 2, 3, 0}
+	ts := Stripify(tri)
+	if ts.Strips != 1 || ts.Triangles != 2 || len(ts.Indices) != 4 {
+		t.Errorf("Expected a single strip of 4 indices, got %v", ts)
+	}
+}
+
+func TestStripifyFixtures(t *testing.T) {
+	for _, name := range []string{"building", "dude", "water", "water2", "hilbert", "eberly-6", "touching-holes", "self-touching", "bad-hole"} {
+		flat, holeIndices, err := loadVertices(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tri, _ := Earcut(flat, holeIndices, 2)
+		ts := Stripify(tri)
+		if ts.Triangles != len(tri)/3 {
+			t.Errorf("Strip has %d triangles, expected %d for %s", ts.Triangles, len(tri)/3, name)
+		}
+		if ts.Strips > ts.Triangles {
+			t.Errorf("More strips than triangles for %s", name)
+		}
+		exp := normalizeTriangles(tri)
+		got := normalizeTriangles(ts.TriangleList())
+		if len(exp) != len(got) {
+			t.Errorf("Strip covers %d triangles, expected %d for %s", len(got), len(exp), name)
+			continue
+		}
+		for i := range exp {
+			if exp[i] != got[i] {
+				t.Errorf("Strip triangles don't match for %s: %v != %v", name, got[i], exp[i])
+				break
+			}
+		}
+	}
+}
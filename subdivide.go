@@ -0,0 +1,138 @@
+package earcut
+
+import (
+	"math"
+)
+
+// EarcutSubdivide triangulates a polygon with Earcut, then splits the
+// triangles with Subdivide.  The arguments are as for Earcut.
+func EarcutSubdivide(data []float64, holeIndices []int, dim int, maxLen float64) ([]float64, []int, error) {
+	triangles, err := Earcut(data, holeIndices, dim)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, triangles = Subdivide(data, holeIndices, dim, triangles, maxLen)
+	return data, triangles, nil
+}
+
+// Subdivide splits triangles until no edge is longer than maxLen, so that
+// the mesh can follow a curved surface or a non-linear projection.  Edge
+// lengths are measured in x and y.
+//
+// The longest edge of a triangle is split at its midpoint, along with the
+// triangle on the other side of it, until every edge is short enough.  A
+// ring edge is thus always cut into 2^k equal parts, for the smallest k
+// that is short enough, however the polygon was triangulated; and ring
+// points that Earcut left out for being collinear are put back first.  So
+// polygons that share an edge still share all of the new vertices along
+// it.
+//
+// The data, holeIndices, dim and triangles are as for Deviation.  The new
+// vertices are appended to a copy of data, with values beyond x and y
+// interpolated from the ends of the edge they split.  If maxLen is not
+// positive, the triangles are returned unchanged.
+//
+// No more vertices are inserted than Refine allows by default: 100
+// times the number of input vertices, or 10000, whichever is larger.  If
+// maxLen needs more than that, splitting stops when the limit is reached
+// and some edges are left longer than maxLen.
+func Subdivide(data []float64, holeIndices []int, dim int, triangles []int, maxLen float64) ([]float64, []int) {
+	r := newRefiner(data, holeIndices, dim, triangles, RefineOptions{})
+	if maxLen <= 0 || r.orient == 0 {
+		return r.data, r.he.Triangles
+	}
+	r.restoreCollinear(data, holeIndices, dim)
+	max2 := maxLen * maxLen
+	for changed := true; changed && r.budget > 0; {
+		changed = false
+		for t := 0; t < len(r.he.Triangles) && r.budget > 0; t += 3 {
+			h, l2 := r.longestEdge(t)
+			if l2 > max2 {
+				p, q := r.he.Origin(h), r.he.Dest(h)
+				x := (r.nodes[p].x + r.nodes[q].x) / 2
+				y := (r.nodes[p].y + r.nodes[q].y) / 2
+				r.splitEdge(h, r.addVertex(x, y, []int{p, q}, []float64{0.5, 0.5}))
+				changed = true
+			}
+		}
+	}
+	return r.data, r.he.Triangles
+}
+
+// longestEdge returns the longest half-edge of the triangle starting at
+// half-edge t, and its squared length
+func (r *refiner) longestEdge(t int) (int, float64) {
+	best, max2 := t, -1.0
+	for h := t; h < t+3; h++ {
+		a, b := r.nodes[r.he.Origin(h)], r.nodes[r.he.Dest(h)]
+		if l2 := (a.x-b.x)*(a.x-b.x) + (a.y-b.y)*(a.y-b.y); l2 > max2 {
+			best, max2 = h, l2
+		}
+	}
+	return best, max2
+}
+
+// restoreCollinear splits boundary edges that span several ring edges at
+// the ring vertices between them, which Earcut drops when they're
+// collinear
+func (r *refiner) restoreCollinear(data []float64, holeIndices []int, dim int) {
+	n := len(data) / dim
+	next := make([]int, n)
+	prev := make([]int, n)
+	for i, l := 0, len(holeIndices); i <= l; i++ {
+		start, end := ringRange(data, holeIndices, dim, i)
+		for j, k := start/dim, end/dim-1; j < end/dim; j++ {
+			next[k] = j
+			prev[j] = k
+			k = j
+		}
+	}
+	for h := 0; h < len(r.he.Triangles); h++ {
+		if r.he.Twin[h] >= 0 || r.isFixed(h) {
+			continue
+		}
+		a, b := r.he.Origin(h), r.he.Dest(h)
+		if a >= n || b >= n {
+			continue
+		}
+		for _, step := range [][]int{next, prev} {
+			if path := r.collinearPath(a, b, step); path != nil {
+				e := h
+				for _, v := range path {
+					// the far half of the edge, from v to b, ends
+					// up in a new triangle at the end of the list
+					r.splitEdge(e, v)
+					e = len(r.he.Triangles) - 3
+				}
+				break
+			}
+		}
+	}
+}
+
+// collinearPath follows a ring from vertex a, using step, and returns the
+// vertices before b if they all lie on the segment from a to b, or nil
+func (r *refiner) collinearPath(a, b int, step []int) []int {
+	pa, pb := r.nodes[a], r.nodes[b]
+	l2 := (pb.x-pa.x)*(pb.x-pa.x) + (pb.y-pa.y)*(pb.y-pa.y)
+	path := []int{}
+	for v, i := step[a], 0; i < len(step); v, i = step[v], i+1 {
+		if r.weld[v] == r.weld[b] {
+			if len(path) == 0 {
+				return nil
+			}
+			return path
+		}
+		p := r.nodes[v]
+		if r.weld[v] == r.weld[a] || (len(path) > 0 && r.weld[v] == r.weld[path[len(path)-1]]) {
+			// a repeated point
+			continue
+		}
+		s := ((p.x-pa.x)*(pb.x-pa.x) + (p.y-pa.y)*(pb.y-pa.y)) / l2
+		if s <= 0 || s >= 1 || math.Abs(orient2d(pa.x, pa.y, pb.x, pb.y, p.x, p.y)) > l2*1e-12 {
+			return nil
+		}
+		path = append(path, v)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package earcut
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func maxEdge(data []float64, dim int, tri []int) float64 {
+	var max float64
+	for i := 0; i < len(tri); i += 3 {
+		for k := 0; k < 3; k++ {
+			a, b := tri[i+k]*dim, tri[i+(k+1)%3]*dim
+			max = math.Max(max, math.Hypot(data[a]-data[b], data[a+1]-data[b+1]))
+		}
+	}
+	return max
+}
+
+// the points of the triangles that lie on the line x = 5, sorted by y
+func pointsOnSeam(data []float64, dim int, tri []int) []float64 {
+	seen := map[int]bool{}
+	ys := []float64{}
+	for _, v := range tri {
+		if data[v*dim] == 5 && !seen[v] {
+			seen[v] = true
+			ys = append(ys, data[v*dim+1])
+		}
+	}
+	sort.Float64s(ys)
+	return ys
+}
+
+func TestSubdivideSharedEdge(t *testing.T) {
+	// two polygons sharing the edge x = 5 from y = 0 to y = 4, with a
+	// collinear point on it, which the left triangulation leaves out
+	// as Earcut sometimes does
+	left := []float64{0, 0, 5, 0, 5, 1.5, 5, 4, 0, 4}
+	leftTri := []int{0, 1, 3, 3, 4, 0}
+	right := []float64{5, 0, 12, 0, 12, 4, 5, 4, 5, 1.5}
+	for _, maxLen := range []float64{0.7, 1, 3} {
+		ld, lt := Subdivide(left, nil, 2, leftTri, maxLen)
+		rd, rt, err := EarcutSubdivide(right, nil, 2, maxLen)
+		if err != nil {
+			t.Fatal("Error making triangles:", err)
+		}
+		if m := maxEdge(ld, 2, lt); m > maxLen {
+			t.Errorf("Edge of length %f for max length %f", m, maxLen)
+		}
+		if m := maxEdge(rd, 2, rt); m > maxLen {
+			t.Errorf("Edge of length %f for max length %f", m, maxLen)
+		}
+		ls, rs := pointsOnSeam(ld, 2, lt), pointsOnSeam(rd, 2, rt)
+		if len(ls) != len(rs) || ls[0] != 0 || ls[len(ls)-1] != 4 || sort.SearchFloat64s(ls, 1.5) == len(ls) || ls[sort.SearchFloat64s(ls, 1.5)] != 1.5 {
+			t.Errorf("Seam points don't match for max length %f: %v %v", maxLen, ls, rs)
+			continue
+		}
+		for i := range ls {
+			if ls[i] != rs[i] {
+				t.Errorf("Seam points don't match for max length %f: %v %v", maxLen, ls, rs)
+				break
+			}
+		}
+		if a := trianglesArea(ld, 2, lt); math.Abs(a-20) > 1e-9 {
+			t.Errorf("Expected area 20, got %f", a)
+		}
+	}
+}
+
+func TestSubdivideInterpolate(t *testing.T) {
+	// a plane, z = x + 2y, and a fourth value that's constant
+	data := []float64{
+		0, 0, 0, 7,
+		8, 0, 8, 7,
+		8, 1, 10, 7,
+		0, 1, 2, 7,
+	}
+	out, tri, err := EarcutSubdivide(data, nil, 4, 0.5)
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	if len(out) == len(data) || len(out)%4 != 0 {
+		t.Fatal("Expected new vertices with 4 values each")
+	}
+	for i := 0; i < len(out); i += 4 {
+		if z := out[i] + 2*out[i+1]; math.Abs(out[i+2]-z) > 1e-9 || out[i+3] != 7 {
+			t.Errorf("Vertex %d has values %v", i/4, out[i+2:i+4])
+		}
+	}
+	if m := maxEdge(out, 4, tri); m > 0.5 {
+		t.Errorf("Edge of length %f", m)
+	}
+
+	tri, _ = Earcut(data, nil, 4)
+	same, tri2 := Subdivide(data, nil, 4, tri, 0)
+	if len(same) != len(data) || !checkVerts(tri, tri2) {
+		t.Error("Expected no change without a max length")
+	}
+}
+
+func TestSubdivideFixtures(t *testing.T) {
+	for _, name := range []string{"building", "dude", "water3", "hole-touching-outer", "touching-holes"} {
+		flat, holeIndices, err := loadVertices(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tri, err := Earcut(flat, holeIndices, 2)
+		if err != nil {
+			t.Fatal("Error in earcut:", err)
+		}
+		maxLen := maxEdge(flat, 2, tri) / 10
+		out, sub := Subdivide(flat, holeIndices, 2, tri, maxLen)
+		if m := maxEdge(out, 2, sub); m > maxLen {
+			t.Errorf("Edge of length %f for max length %f for %s", m, maxLen, name)
+		}
+		exp := trianglesArea(flat, 2, tri)
+		if a := trianglesArea(out, 2, sub); math.Abs(a-exp) > exp*1e-9 {
+			t.Errorf("Expected area %f, got %f for %s", exp, a, name)
+		}
+	}
+}
+
+func TestSubdivideLimit(t *testing.T) {
+	data := []float64{0, 0, 10, 0, 10, 10, 0, 10}
+	tri, err := Earcut(data, nil, 2)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	out, sub := Subdivide(data, nil, 2, tri, 0.01)
+	if n := len(out)/2 - 4; n > 10000 {
+		t.Errorf("Expected at most 10000 new vertices, got %d", n)
+	}
+	if a := trianglesArea(out, 2, sub); math.Abs(a-100) > 1e-9 {
+		t.Errorf("Expected area 100, got %f", a)
+	}
+}
@@ -0,0 +1,214 @@
+package earcut
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// SVGOptions controls the output of WriteSVG.  Empty style strings and
+// zero sizes fall back to the defaults in DefaultSVGOptions.
+type SVGOptions struct {
+	// Width is the width of the image in pixels; the height follows from
+	// the aspect ratio of the polygon.  Margin is added on each side.
+	Width  float64
+	Margin float64
+
+	// Styles are inline SVG style attributes for each layer.  Unused is
+	// the fill drawn under the triangles, so it only shows through where
+	// the polygon isn't covered.
+	OuterStyle    string
+	HoleStyle     string
+	TriangleStyle string
+	BridgeStyle   string
+	UnusedStyle   string
+	DroppedStyle  string
+
+	// VertexRadius is the radius of the dots marking dropped vertices.
+	VertexRadius float64
+
+	// Labels adds the vertex index next to each dropped vertex.
+	Labels bool
+}
+
+// DefaultSVGOptions returns the options used by WriteSVG when nil is
+// passed.
+func DefaultSVGOptions() *SVGOptions {
+	return &SVGOptions{
+		Width:         800,
+		Margin:        10,
+		OuterStyle:    "fill:none;stroke:#000;stroke-width:1.5",
+		HoleStyle:     "fill:none;stroke:#06c;stroke-width:1.5",
+		TriangleStyle: "fill:#dde8f4;stroke:#8a9bb0;stroke-width:0.5;stroke-linejoin:round",
+		BridgeStyle:   "fill:none;stroke:#f80;stroke-width:1.5;stroke-dasharray:4,3",
+		UnusedStyle:   "fill:#f33;fill-rule:evenodd;stroke:none",
+		DroppedStyle:  "fill:#d00;stroke:none",
+		VertexRadius:  3,
+	}
+}
+
+func (opts *SVGOptions) withDefaults() *SVGOptions {
+	def := DefaultSVGOptions()
+	if opts == nil {
+		return def
+	}
+	o := *opts
+	if o.Width <= 0 {
+		o.Width = def.Width
+	}
+	if o.Margin < 0 {
+		o.Margin = 0
+	}
+	if o.VertexRadius <= 0 {
+		o.VertexRadius = def.VertexRadius
+	}
+	for _, s := range []struct {
+		dst *string
+		def string
+	}{
+		{&o.OuterStyle, def.OuterStyle},
+		{&o.HoleStyle, def.HoleStyle},
+		{&o.TriangleStyle, def.TriangleStyle},
+		{&o.BridgeStyle, def.BridgeStyle},
+		{&o.UnusedStyle, def.UnusedStyle},
+		{&o.DroppedStyle, def.DroppedStyle},
+	} {
+		if *s.dst == "" {
+			*s.dst = s.def
+		}
+	}
+	return &o
+}
+
+// WriteSVG draws a polygon and its triangulation as an SVG image, for
+// debugging.  The arguments are as for Deviation.
+//
+// From the bottom up, the layers are: the polygon area, filled with
+// UnusedStyle; the triangles; the outer ring and holes; the bridges that
+// earcut uses to join each hole to the outer ring; and the vertices that
+// aren't used by any triangle.  Any area of the polygon that the
+// triangulation misses is left showing in the unused color.
+//
+// Dropped vertices include repeated closing points and collinear points,
+// which earcut removes on purpose, as well as vertices lost to a bad
+// triangulation.
+func WriteSVG(w io.Writer, data []float64, holeIndices []int, dim int, triangles []int, opts *SVGOptions) error {
+	if dim < 2 {
+		return fmt.Errorf("need at least 2 dimensions")
+	}
+	opts = opts.withDefaults()
+	n := len(data) / dim
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i < n; i++ {
+		x, y := data[i*dim], data[i*dim+1]
+		minX = math.Min(minX, x)
+		minY = math.Min(minY, y)
+		maxX = math.Max(maxX, x)
+		maxY = math.Max(maxY, y)
+	}
+	if n == 0 {
+		minX, minY, maxX, maxY = 0, 0, 1, 1
+	}
+	size := math.Max(maxX-minX, maxY-minY)
+	if size == 0 {
+		size = 1
+	}
+	scale := opts.Width / size
+	width := (maxX-minX)*scale + 2*opts.Margin
+	height := (maxY-minY)*scale + 2*opts.Margin
+
+	// SVG y runs down the page
+	px := func(i int) (float64, float64) {
+		return opts.Margin + (data[i*dim]-minX)*scale, opts.Margin + (maxY-data[i*dim+1])*scale
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		svgNum(width), svgNum(height), svgNum(width), svgNum(height))
+
+	// ring paths, reused for the unused fill and the outlines
+	rings := make([]string, len(holeIndices)+1)
+	for r := range rings {
+		start, end := ringRange(data, holeIndices, dim, r)
+		var sb strings.Builder
+		for i := start / dim; i < end/dim; i++ {
+			x, y := px(i)
+			if i == start/dim {
+				sb.WriteString("M")
+			} else {
+				sb.WriteString(" L")
+			}
+			sb.WriteString(svgNum(x) + "," + svgNum(y))
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(" Z")
+		}
+		rings[r] = sb.String()
+	}
+
+	fmt.Fprintf(bw, "<path class=\"unused\" style=\"%s\" d=\"%s\"/>\n", svgAttr(opts.UnusedStyle), strings.Join(rings, " "))
+
+	used := make([]bool, n)
+	fmt.Fprintf(bw, "<g class=\"triangles\" style=\"%s\">\n", svgAttr(opts.TriangleStyle))
+	for i := 0; i+2 < len(triangles); i += 3 {
+		a, b, c := triangles[i], triangles[i+1], triangles[i+2]
+		used[a], used[b], used[c] = true, true, true
+		ax, ay := px(a)
+		bx, by := px(b)
+		cx, cy := px(c)
+		fmt.Fprintf(bw, "<path d=\"M%s,%s L%s,%s L%s,%s Z\"/>\n",
+			svgNum(ax), svgNum(ay), svgNum(bx), svgNum(by), svgNum(cx), svgNum(cy))
+	}
+	fmt.Fprintln(bw, "</g>")
+
+	for r, d := range rings {
+		if d == "" {
+			continue
+		}
+		class, style := "outer", opts.OuterStyle
+		if r > 0 {
+			class, style = "hole", opts.HoleStyle
+		}
+		fmt.Fprintf(bw, "<path class=\"%s\" style=\"%s\" d=\"%s\"/>\n", class, svgAttr(style), d)
+	}
+
+	fmt.Fprintf(bw, "<g class=\"bridges\" style=\"%s\">\n", svgAttr(opts.BridgeStyle))
+	for _, br := range holeBridges(data, holeIndices, dim) {
+		ax, ay := px(br[0])
+		bx, by := px(br[1])
+		fmt.Fprintf(bw, "<path d=\"M%s,%s L%s,%s\"/>\n", svgNum(ax), svgNum(ay), svgNum(bx), svgNum(by))
+	}
+	fmt.Fprintln(bw, "</g>")
+
+	fmt.Fprintf(bw, "<g class=\"dropped\" style=\"%s\">\n", svgAttr(opts.DroppedStyle))
+	for i, u := range used {
+		if u {
+			continue
+		}
+		x, y := px(i)
+		fmt.Fprintf(bw, "<circle cx=\"%s\" cy=\"%s\" r=\"%s\"/>\n", svgNum(x), svgNum(y), svgNum(opts.VertexRadius))
+		if opts.Labels {
+			fmt.Fprintf(bw, "<text x=\"%s\" y=\"%s\" font-size=\"10\">%d</text>\n",
+				svgNum(x+opts.VertexRadius), svgNum(y-opts.VertexRadius), i)
+		}
+	}
+	fmt.Fprintln(bw, "</g>")
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func svgNum(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+var svgEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;", "'", "&apos;")
+
+// svgAttr escapes a string for use in an attribute value
+func svgAttr(s string) string {
+	return svgEscaper.Replace(s)
+}
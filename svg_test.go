@@ -0,0 +1,123 @@
+package earcut
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+type svgElem struct {
+	XMLName  xml.Name
+	Class    string    `xml:"class,attr"`
+	Style    string    `xml:"style,attr"`
+	D        string    `xml:"d,attr"`
+	Text     string    `xml:",chardata"`
+	Children []svgElem `xml:",any"`
+}
+
+func parseSVG(b []byte, t *testing.T) map[string]svgElem {
+	root := svgElem{}
+	if err := xml.Unmarshal(b, &root); err != nil {
+		t.Fatal("Error parsing SVG:", err)
+	}
+	if root.XMLName.Local != "svg" {
+		t.Fatal("Root element is not svg:", root.XMLName.Local)
+	}
+	layers := map[string]svgElem{}
+	for _, el := range root.Children {
+		layers[el.Class] = el
+	}
+	return layers
+}
+
+func TestWriteSVG(t *testing.T) {
+	data, holeIndices := squareWithHole().flatten()
+	tri, err := Earcut(data, holeIndices, 2)
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	buf := &bytes.Buffer{}
+	if err = WriteSVG(buf, data, holeIndices, 2, tri, nil); err != nil {
+		t.Fatal("Error writing SVG:", err)
+	}
+	layers := parseSVG(buf.Bytes(), t)
+	if n := len(layers["triangles"].Children); n != len(tri)/3 {
+		t.Errorf("Expected %d triangles, got %d", len(tri)/3, n)
+	}
+	if n := len(layers["bridges"].Children); n != 1 {
+		t.Errorf("Expected 1 bridge, got %d", n)
+	}
+	// only the repeated closing points go unused
+	if n := len(layers["dropped"].Children); n != 2 {
+		t.Errorf("Expected 2 dropped vertices, got %d", n)
+	}
+	if _, ok := layers["outer"]; !ok {
+		t.Error("Outer ring missing")
+	}
+	if strings.Count(layers["unused"].D, "M") != 2 {
+		t.Error("Unused area should include both rings:", layers["unused"].D)
+	}
+
+	// losing a triangle leaves its vertices unused
+	buf.Reset()
+	opts := &SVGOptions{Labels: true, DroppedStyle: "fill:blue"}
+	if err = WriteSVG(buf, data, holeIndices, 2, tri[3:], opts); err != nil {
+		t.Fatal("Error writing SVG:", err)
+	}
+	layers = parseSVG(buf.Bytes(), t)
+	dropped := layers["dropped"]
+	if dropped.Style != "fill:blue" {
+		t.Error("Dropped style not applied:", dropped.Style)
+	}
+	if layers["outer"].Style != DefaultSVGOptions().OuterStyle {
+		t.Error("Default style not applied:", layers["outer"].Style)
+	}
+	circles := 0
+	for _, el := range dropped.Children {
+		if el.XMLName.Local == "circle" {
+			circles++
+		}
+	}
+	if circles < 2 || circles != len(dropped.Children)/2 {
+		t.Errorf("Expected labelled dropped vertices, got %d elements", len(dropped.Children))
+	}
+}
+
+func TestHoleBridges(t *testing.T) {
+	for _, name := range []string{"building", "water", "hole-touching-outer", "touching-holes", "steiner"} {
+		flat, holeIndices, err := loadVertices(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bridges := holeBridges(flat, holeIndices, 2)
+		if len(bridges) > len(holeIndices) {
+			t.Errorf("Expected at most %d bridges, got %d for %s", len(holeIndices), len(bridges), name)
+		}
+		for _, br := range bridges {
+			if br[1] < holeIndices[0] {
+				t.Errorf("Bridge %v doesn't end on a hole for %s", br, name)
+			}
+		}
+		if name == "water" && len(bridges) != len(holeIndices) {
+			t.Errorf("Expected %d bridges, got %d for %s", len(holeIndices), len(bridges), name)
+		}
+	}
+}
+
+func TestWriteSVGEscapesStyles(t *testing.T) {
+	data, holeIndices := squareWithHole().flatten()
+	tri, err := Earcut(data, holeIndices, 2)
+	if err != nil {
+		t.Fatal("Error making triangles:", err)
+	}
+	style := `font-family:"A&B" <x>'s`
+	buf := &bytes.Buffer{}
+	if err = WriteSVG(buf, data, holeIndices, 2, tri, &SVGOptions{OuterStyle: style, BridgeStyle: style}); err != nil {
+		t.Fatal("Error writing SVG:", err)
+	}
+	layers := parseSVG(buf.Bytes(), t)
+	if layers["outer"].Style != style || layers["bridges"].Style != style {
+		t.Errorf("Expected style %q, got %q and %q", style, layers["outer"].Style, layers["bridges"].Style)
+	}
+}
@@ -0,0 +1,636 @@
+// Package svgpath parses SVG path data into polygons that can be
+// triangulated with earcut.
+//
+// Curves and arcs are flattened into line segments, the path is split into
+// subpaths, and the subpaths are grouped into outer rings and holes
+// according to the path's fill rule.
+package svgpath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// FillRule selects how overlapping subpaths are filled.
+type FillRule int
+
+// SVG fill rules
+const (
+	NonZero FillRule = iota
+	EvenOdd
+)
+
+// SyntaxError describes a problem with the path data and where it
+// occurred.
+type SyntaxError struct {
+	// Offset is the byte offset of the problem in the path data.
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("svgpath: offset %d: %s", e.Offset, e.Msg)
+}
+
+// Polygons parses path data, flattens it to within tolerance, and groups
+// the subpaths into polygons according to rule.
+func Polygons(d string, tolerance float64, rule FillRule) ([]earcut.Polygon, error) {
+	rings, err := Parse(d, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	return Classify(rings, rule), nil
+}
+
+// Parse parses path data into one ring per subpath.  Curves and arcs are
+// replaced by line segments that stay within tolerance of the true curve.
+// Every subpath is treated as closed, as it is when the path is filled.
+// Subpaths with fewer than 3 points enclose no area and are dropped.
+func Parse(d string, tolerance float64) ([]earcut.Ring, error) {
+	if !(tolerance > 0) {
+		return nil, fmt.Errorf("svgpath: tolerance must be positive")
+	}
+	p := &parser{src: d, tol: tolerance, rings: []earcut.Ring{}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	p.closeSubpath()
+	return p.rings, nil
+}
+
+type parser struct {
+	src   string
+	pos   int
+	tol   float64
+	rings []earcut.Ring
+	ring  earcut.Ring
+
+	// current point, subpath start point, and the last control point
+	// for the smooth curve commands
+	cur   earcut.Point
+	start earcut.Point
+	ctrl  earcut.Point
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// skip whitespace and at most one comma
+func (p *parser) skipSeparator() {
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == ',' {
+		p.pos++
+		p.skipSpace()
+	}
+}
+
+// report whether a number starts at the current position
+func (p *parser) atNumber() bool {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return false
+	}
+	c := p.src[p.pos]
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.'
+}
+
+func (p *parser) number() (float64, error) {
+	p.skipSpace()
+	start := p.pos
+	i := p.pos
+	if i < len(p.src) && (p.src[i] == '-' || p.src[i] == '+') {
+		i++
+	}
+	digits := 0
+	for i < len(p.src) && p.src[i] >= '0' && p.src[i] <= '9' {
+		i++
+		digits++
+	}
+	if i < len(p.src) && p.src[i] == '.' {
+		i++
+		for i < len(p.src) && p.src[i] >= '0' && p.src[i] <= '9' {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, p.errorf("expected number")
+	}
+	if i < len(p.src) && (p.src[i] == 'e' || p.src[i] == 'E') {
+		j := i + 1
+		if j < len(p.src) && (p.src[j] == '-' || p.src[j] == '+') {
+			j++
+		}
+		if j < len(p.src) && p.src[j] >= '0' && p.src[j] <= '9' {
+			for j < len(p.src) && p.src[j] >= '0' && p.src[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	v, err := strconv.ParseFloat(p.src[start:i], 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", p.src[start:i])
+	}
+	p.pos = i
+	p.skipSeparator()
+	return v, nil
+}
+
+// arc flags are a single 0 or 1, and need not be separated from what
+// follows them
+func (p *parser) flag() (bool, error) {
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '0':
+			p.pos++
+			p.skipSeparator()
+			return false, nil
+		case '1':
+			p.pos++
+			p.skipSeparator()
+			return true, nil
+		}
+	}
+	return false, p.errorf("expected flag")
+}
+
+func (p *parser) numbers(n int) ([]float64, error) {
+	vals := make([]float64, n)
+	for i := range vals {
+		v, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+func (p *parser) parse() error {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil
+	}
+	if c := p.src[p.pos]; c != 'M' && c != 'm' {
+		return p.errorf("path data must start with a moveto")
+	}
+	var prev byte
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil
+		}
+		cmd := p.src[p.pos]
+		p.pos++
+		if err := p.command(cmd, prev); err != nil {
+			return err
+		}
+		prev = cmd
+	}
+}
+
+// run a command and any implicit repetitions of it
+func (p *parser) command(cmd, prev byte) error {
+	rel := cmd >= 'a' && cmd <= 'z'
+	upper := cmd &^ 0x20
+	if upper == 'Z' {
+		p.closePath()
+		return nil
+	}
+	first := true
+	for first || p.atNumber() {
+		var err error
+		switch upper {
+		case 'M':
+			if first {
+				err = p.moveTo(rel)
+			} else {
+				err = p.lineTo(rel)
+			}
+		case 'L':
+			err = p.lineTo(rel)
+		case 'H', 'V':
+			err = p.axisLineTo(upper, rel)
+		case 'C', 'S':
+			err = p.cubicTo(upper == 'S', rel, prev)
+		case 'Q', 'T':
+			err = p.quadTo(upper == 'T', rel, prev)
+		case 'A':
+			err = p.arcTo(rel)
+		default:
+			p.pos--
+			return p.errorf("unknown command %q", cmd)
+		}
+		if err != nil {
+			return err
+		}
+		first = false
+		prev = cmd
+	}
+	return nil
+}
+
+func (p *parser) point(rel bool) (earcut.Point, error) {
+	vals, err := p.numbers(2)
+	if err != nil {
+		return earcut.Point{}, err
+	}
+	pt := earcut.Point{X: vals[0], Y: vals[1]}
+	if rel {
+		pt.X += p.cur.X
+		pt.Y += p.cur.Y
+	}
+	return pt, nil
+}
+
+// finish the current subpath, if it encloses any area
+func (p *parser) closeSubpath() {
+	n := len(p.ring)
+	if n > 1 && p.ring[0] == p.ring[n-1] {
+		p.ring = p.ring[:n-1]
+	}
+	if len(p.ring) >= 3 {
+		p.rings = append(p.rings, p.ring)
+	}
+	p.ring = nil
+}
+
+// add a point to the current subpath, starting a new one at the current
+// point if needed (such as after a closepath)
+func (p *parser) emit(pt earcut.Point) {
+	if p.ring == nil {
+		p.ring = earcut.Ring{p.cur}
+	}
+	if pt != p.ring[len(p.ring)-1] {
+		p.ring = append(p.ring, pt)
+	}
+}
+
+func (p *parser) moveTo(rel bool) error {
+	pt, err := p.point(rel)
+	if err != nil {
+		return err
+	}
+	p.closeSubpath()
+	p.cur = pt
+	p.start = pt
+	p.ctrl = pt
+	p.ring = earcut.Ring{pt}
+	return nil
+}
+
+func (p *parser) closePath() {
+	p.closeSubpath()
+	p.cur = p.start
+	p.ctrl = p.start
+}
+
+func (p *parser) lineTo(rel bool) error {
+	pt, err := p.point(rel)
+	if err != nil {
+		return err
+	}
+	p.emit(pt)
+	p.cur = pt
+	p.ctrl = pt
+	return nil
+}
+
+func (p *parser) axisLineTo(axis byte, rel bool) error {
+	v, err := p.number()
+	if err != nil {
+		return err
+	}
+	pt := p.cur
+	if axis == 'H' {
+		if rel {
+			v += pt.X
+		}
+		pt.X = v
+	} else {
+		if rel {
+			v += pt.Y
+		}
+		pt.Y = v
+	}
+	p.emit(pt)
+	p.cur = pt
+	p.ctrl = pt
+	return nil
+}
+
+// the first control point of a smooth curve is the reflection of the
+// previous curve's last control point, if the previous command was the
+// same kind of curve
+func (p *parser) reflect(prev byte, kinds string) earcut.Point {
+	upper := prev &^ 0x20
+	if prev == 0 || (upper != kinds[0] && upper != kinds[1]) {
+		return p.cur
+	}
+	return earcut.Point{X: 2*p.cur.X - p.ctrl.X, Y: 2*p.cur.Y - p.ctrl.Y}
+}
+
+func (p *parser) cubicTo(smooth, rel bool, prev byte) error {
+	var c1 earcut.Point
+	var err error
+	if smooth {
+		c1 = p.reflect(prev, "CS")
+	} else if c1, err = p.point(rel); err != nil {
+		return err
+	}
+	c2, err := p.point(rel)
+	if err != nil {
+		return err
+	}
+	end, err := p.point(rel)
+	if err != nil {
+		return err
+	}
+	p.flattenCubic(p.cur, c1, c2, end, 0)
+	p.cur = end
+	p.ctrl = c2
+	return nil
+}
+
+func (p *parser) quadTo(smooth, rel bool, prev byte) error {
+	var c earcut.Point
+	var err error
+	if smooth {
+		c = p.reflect(prev, "QT")
+	} else if c, err = p.point(rel); err != nil {
+		return err
+	}
+	end, err := p.point(rel)
+	if err != nil {
+		return err
+	}
+	// a quadratic is a cubic with control points 2/3 of the way to
+	// the quadratic control point
+	c1 := earcut.Point{X: p.cur.X + 2.0/3.0*(c.X-p.cur.X), Y: p.cur.Y + 2.0/3.0*(c.Y-p.cur.Y)}
+	c2 := earcut.Point{X: end.X + 2.0/3.0*(c.X-end.X), Y: end.Y + 2.0/3.0*(c.Y-end.Y)}
+	p.flattenCubic(p.cur, c1, c2, end, 0)
+	p.cur = end
+	p.ctrl = c
+	return nil
+}
+
+func (p *parser) arcTo(rel bool) error {
+	radii, err := p.numbers(3)
+	if err != nil {
+		return err
+	}
+	large, err := p.flag()
+	if err != nil {
+		return err
+	}
+	sweep, err := p.flag()
+	if err != nil {
+		return err
+	}
+	end, err := p.point(rel)
+	if err != nil {
+		return err
+	}
+	p.flattenArc(p.cur, end, radii[0], radii[1], radii[2], large, sweep)
+	p.cur = end
+	p.ctrl = end
+	return nil
+}
+
+// maximum recursion depth when flattening curves; arcs are likewise cut
+// into at most 2^maxDepth segments
+const maxDepth = 16
+
+// distance from pt to the line through a and b
+func lineDistance(pt, a, b earcut.Point) float64 {
+	dx := b.X - a.X
+	dy := b.Y - a.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return math.Hypot(pt.X-a.X, pt.Y-a.Y)
+	}
+	return math.Abs((pt.X-a.X)*dy-(pt.Y-a.Y)*dx) / l
+}
+
+func mid(a, b earcut.Point) earcut.Point {
+	return earcut.Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
+
+// flatten a cubic bezier by recursive subdivision until the control points
+// are within tolerance of the chord
+func (p *parser) flattenCubic(p0, p1, p2, p3 earcut.Point, depth int) {
+	if depth >= maxDepth ||
+		(lineDistance(p1, p0, p3) <= p.tol && lineDistance(p2, p0, p3) <= p.tol) {
+		p.emit(p3)
+		return
+	}
+	p01 := mid(p0, p1)
+	p12 := mid(p1, p2)
+	p23 := mid(p2, p3)
+	p012 := mid(p01, p12)
+	p123 := mid(p12, p23)
+	m := mid(p012, p123)
+	p.flattenCubic(p0, p01, p012, m, depth+1)
+	p.flattenCubic(m, p123, p23, p3, depth+1)
+}
+
+// flatten an elliptical arc, converting from the SVG endpoint
+// parameterization to a center parameterization as described in the SVG
+// implementation notes
+func (p *parser) flattenArc(from, to earcut.Point, rx, ry, angle float64, large, sweep bool) {
+	if from == to {
+		return
+	}
+	rx = math.Abs(rx)
+	ry = math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.emit(to)
+		return
+	}
+	phi := angle * math.Pi / 180
+	sin, cos := math.Sincos(phi)
+	dx := (from.X - to.X) / 2
+	dy := (from.Y - to.Y) / 2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// scale up radii that are too small to reach the end point
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		s := math.Sqrt(l)
+		rx *= s
+		ry *= s
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	cx := cos*cx1 - sin*cy1 + (from.X+to.X)/2
+	cy := sin*cx1 + cos*cy1 + (from.Y+to.Y)/2
+
+	theta := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	// choose a step so the sagitta of each segment is within tolerance
+	r := math.Max(rx, ry)
+	step := math.Pi / 2
+	if p.tol < r {
+		step = math.Min(step, 2*math.Acos(1-p.tol/r))
+	}
+	n := 1 << maxDepth
+	if f := math.Ceil(math.Abs(delta) / step); f < float64(n) {
+		n = int(f)
+	}
+	if n < 1 {
+		n = 1
+	}
+	for i := 1; i < n; i++ {
+		t := theta + delta*float64(i)/float64(n)
+		ex := rx * math.Cos(t)
+		ey := ry * math.Sin(t)
+		p.emit(earcut.Point{X: cos*ex - sin*ey + cx, Y: sin*ex + cos*ey + cy})
+	}
+	p.emit(to)
+}
+
+// signed area of a ring; positive for counter-clockwise rings in a y-up
+// coordinate system
+func signedArea(ring earcut.Ring) float64 {
+	var sum float64
+	for i, j := 0, len(ring)-1; i < len(ring); i++ {
+		sum += (ring[j].X - ring[i].X) * (ring[i].Y + ring[j].Y)
+		j = i
+	}
+	return sum / 2
+}
+
+// check whether a point is inside a ring, using the even-odd rule
+func contains(ring earcut.Ring, pt earcut.Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); i++ {
+		a, b := ring[i], ring[j]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) &&
+			pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
+
+// find a point strictly inside a ring, near its first vertex, to decide
+// which other rings contain it
+func interiorPoint(ring earcut.Ring) earcut.Point {
+	for i := range ring {
+		a := ring[(i+len(ring)-1)%len(ring)]
+		b := ring[i]
+		c := ring[(i+1)%len(ring)]
+		pt := earcut.Point{X: (a.X + b.X + c.X) / 3, Y: (a.Y + b.Y + c.Y) / 3}
+		if contains(ring, pt) {
+			return pt
+		}
+	}
+	return mid(ring[0], ring[len(ring)/2])
+}
+
+// Classify groups rings into polygons.  Each ring becomes either an outer
+// ring or a hole of the smallest outer ring enclosing it, based on its
+// nesting depth and, for the non-zero rule, its winding direction.  Rings
+// that don't bound a filled region under the fill rule are dropped.
+// Subpaths are assumed not to cross each other.
+func Classify(rings []earcut.Ring, rule FillRule) []earcut.Polygon {
+	type info struct {
+		ring  earcut.Ring
+		area  float64
+		point earcut.Point
+		outer int
+		hole  bool
+	}
+	infos := make([]*info, 0, len(rings))
+	for _, ring := range rings {
+		if len(ring) < 3 {
+			continue
+		}
+		a := signedArea(ring)
+		if a == 0 {
+			continue
+		}
+		infos = append(infos, &info{ring: ring, area: a, outer: -1})
+	}
+
+	// process rings from largest to smallest, so every ring that can
+	// enclose a ring has been classified before it
+	sort.SliceStable(infos, func(i, j int) bool {
+		return math.Abs(infos[i].area) > math.Abs(infos[j].area)
+	})
+	polys := []earcut.Polygon{}
+	for i, r := range infos {
+		r.point = interiorPoint(r.ring)
+		winding := 0
+		parent := -1
+		for j := 0; j < i; j++ {
+			if !contains(infos[j].ring, r.point) {
+				continue
+			}
+			if rule == EvenOdd {
+				winding++
+			} else if infos[j].area > 0 {
+				winding++
+			} else {
+				winding--
+			}
+			if infos[j].outer >= 0 && !infos[j].hole {
+				parent = j
+			}
+		}
+		inside := winding
+		if rule == EvenOdd {
+			inside++
+		} else if r.area > 0 {
+			inside++
+		} else {
+			inside--
+		}
+		filled := func(w int) bool {
+			if rule == EvenOdd {
+				return w%2 != 0
+			}
+			return w != 0
+		}
+		switch {
+		case !filled(winding) && filled(inside):
+			r.outer = len(polys)
+			polys = append(polys, earcut.Polygon{Outer: r.ring})
+		case filled(winding) && !filled(inside) && parent >= 0:
+			r.hole = true
+			r.outer = infos[parent].outer
+			polys[r.outer].Holes = append(polys[r.outer].Holes, r.ring)
+		}
+	}
+	return polys
+}
@@ -0,0 +1,212 @@
+package svgpath
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+func TestParseCommands(t *testing.T) {
+	tests := []struct {
+		d   string
+		exp [][][2]float64
+	}{
+		{"M0 0 L10 0 L10 10 Z", [][][2]float64{{{0, 0}, {10, 0}, {10, 10}}}},
+		{"m0,0 10,0 0,10 z", [][][2]float64{{{0, 0}, {10, 0}, {10, 10}}}},
+		{"M0 0H10V10H0Z", [][][2]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}},
+		{"M5 5h10v10h-10z m20 0 h10 v10 h-10 z", [][][2]float64{
+			{{5, 5}, {15, 5}, {15, 15}, {5, 15}},
+			{{25, 5}, {35, 5}, {35, 15}, {25, 15}},
+		}},
+		{"M0-5L1e1-5L.5.5z", [][][2]float64{{{0, -5}, {10, -5}, {0.5, 0.5}}}},
+		{"M0 0 L10 0 Z L0 10 L-10 0", [][][2]float64{{{0, 0}, {0, 10}, {-10, 0}}}},
+		{"M0 0 L10 0", [][][2]float64{}},
+		{"", [][][2]float64{}},
+	}
+	for _, test := range tests {
+		rings, err := Parse(test.d, 0.1)
+		if err != nil {
+			t.Errorf("Error parsing %q: %s", test.d, err)
+			continue
+		}
+		if len(rings) != len(test.exp) {
+			t.Errorf("Expected %d rings for %q, got %d", len(test.exp), test.d, len(rings))
+			continue
+		}
+		for i, ring := range rings {
+			if len(ring) != len(test.exp[i]) {
+				t.Errorf("Ring %d of %q doesn't match: %v", i, test.d, ring)
+				continue
+			}
+			for j, pt := range ring {
+				if math.Abs(pt.X-test.exp[i][j][0]) > 1e-12 || math.Abs(pt.Y-test.exp[i][j][1]) > 1e-12 {
+					t.Errorf("Ring %d of %q doesn't match: %v", i, test.d, ring)
+					break
+				}
+			}
+		}
+	}
+}
+
+// check that every point of every ring lies within tol of a circle
+func checkCircle(rings []earcut.Ring, cx, cy, r, tol float64, t *testing.T) {
+	if len(rings) != 1 {
+		t.Fatalf("Expected 1 ring, got %d", len(rings))
+	}
+	if len(rings[0]) < 8 {
+		t.Errorf("Expected circle to be flattened to many points, got %d", len(rings[0]))
+	}
+	for _, pt := range rings[0] {
+		if d := math.Abs(math.Hypot(pt.X-cx, pt.Y-cy) - r); d > tol {
+			t.Errorf("Point %v is %f away from circle", pt, d)
+		}
+	}
+	area := math.Abs(signedArea(rings[0]))
+	if math.Abs(area-math.Pi*r*r)/(math.Pi*r*r) > 0.01 {
+		t.Errorf("Circle area %f too far from %f", area, math.Pi*r*r)
+	}
+}
+
+func TestParseArcs(t *testing.T) {
+	for _, d := range []string{
+		"M0 10 A10 10 0 0 1 20 10 A10 10 0 0 1 0 10 Z",
+		"M0 10 a10 10 0 1 0 20 0 a10 10 0 1 0 -20 0",
+		"M0 10a10 10 0 1020 0a10 10 0 10-20 0",
+		"M0 10 A5 5 0 0 1 20 10 A5 5 0 0 1 0 10 Z",
+	} {
+		rings, err := Parse(d, 0.01)
+		if err != nil {
+			t.Errorf("Error parsing %q: %s", d, err)
+			continue
+		}
+		checkCircle(rings, 10, 10, 10, 0.01, t)
+	}
+	rings, err := Parse("M0 0 A10 20 90 0 1 40 0 Z", 0.01)
+	if err != nil {
+		t.Fatal("Error parsing rotated arc:", err)
+	}
+	for _, pt := range rings[0] {
+		if pt.Y > 1e-9 {
+			t.Errorf("Point %v on wrong side of rotated arc", pt)
+		}
+	}
+	// a tolerance far below the radius is limited like curve depth
+	for _, tol := range []float64{1e-6, 1e-12} {
+		rings, err = Parse("M0 0 A1e6 1e6 0 0 1 0 2e6 Z", tol)
+		if err != nil {
+			t.Fatal("Error parsing large arc:", err)
+		}
+		if n := len(rings[0]); n < 1000 || n > 1<<maxDepth+1 {
+			t.Errorf("Expected up to %d points for large arc with tolerance %g, got %d", 1<<maxDepth+1, tol, n)
+		}
+	}
+}
+
+func TestParseCurves(t *testing.T) {
+	// a circle of radius 10 approximated by 4 cubic curves
+	k := 10 * 0.5522847498
+	d := "M20 10 C20 " + ftoa(10+k) + " " + ftoa(10+k) + " 20 10 20 " +
+		"S0 " + ftoa(10+k) + " 0 10 S" + ftoa(10-k) + " 0 10 0 S20 " + ftoa(10-k) + " 20 10 Z"
+	rings, err := Parse(d, 0.001)
+	if err != nil {
+		t.Fatalf("Error parsing %q: %s", d, err)
+	}
+	checkCircle(rings, 10, 10, 10, 0.03, t)
+	rings, err = Parse("M0 0 Q10 10 20 0 T40 0 L40 -10 L0 -10 Z", 0.001)
+	if err != nil {
+		t.Fatal("Error parsing quadratic curves:", err)
+	}
+	var maxY, minY float64
+	for _, pt := range rings[0] {
+		maxY = math.Max(maxY, pt.Y)
+		minY = math.Min(minY, pt.Y)
+	}
+	if math.Abs(maxY-5) > 0.001 || minY != -10 {
+		t.Errorf("Quadratic curve extents don't match: %f, %f", maxY, minY)
+	}
+	rings, err = Parse("M0 0 q10 10 20 0 t20 0 v-10 h-40 z", 0.001)
+	if err != nil {
+		t.Fatal("Error parsing relative quadratic curves:", err)
+	}
+	for _, pt := range rings[0] {
+		if pt.X > 20 && pt.Y > 1e-9 {
+			t.Errorf("Smooth quadratic not reflected at %v", pt)
+		}
+	}
+}
+
+func ftoa(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		d      string
+		offset int
+	}{
+		{"L0 0", 0},
+		{"M0 0 L10", 8},
+		{"M0 0 X10 10", 5},
+		{"M0 0 A10 10 0 2 1 5 5", 14},
+		{"M0 0 L1 1 -", 10},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.d, 0.1)
+		serr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Expected *SyntaxError parsing %q, got %v", test.d, err)
+			continue
+		}
+		if serr.Offset != test.offset {
+			t.Errorf("Expected error at offset %d parsing %q, got %s", test.offset, test.d, serr)
+		}
+	}
+	if _, err := Parse("M0 0 L1 1 L0 1 Z", 0); err == nil {
+		t.Error("Expected error for zero tolerance")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	// outer square, hole square, island inside the hole, and a
+	// separate square; the inner rings wind the same way as the outer
+	d := "M0 0 H100 V100 H0 Z M10 10 H90 V90 H10 Z M40 40 H60 V60 H40 Z M200 0 H210 V10 H200 Z"
+	polys, err := Polygons(d, 0.1, EvenOdd)
+	if err != nil {
+		t.Fatal("Error parsing path:", err)
+	}
+	if len(polys) != 3 {
+		t.Fatalf("Expected 3 polygons with even-odd rule, got %d", len(polys))
+	}
+	if len(polys[0].Holes) != 1 || polys[0].Outer[0] != (earcut.Point{X: 0, Y: 0}) {
+		t.Error("Outer polygon doesn't match", polys[0])
+	}
+	var area float64
+	for _, poly := range polys {
+		tri, err := poly.Triangulate()
+		if err != nil {
+			t.Error("Error triangulating:", err)
+		}
+		if len(tri) == 0 {
+			t.Error("Expected triangles for polygon", poly)
+		}
+		area += poly.Area()
+	}
+	if exp := 100.0*100 - 80*80 + 20*20 + 10*10; area != exp {
+		t.Errorf("Expected area %f, got %f", exp, area)
+	}
+
+	// with the non-zero rule, same-direction inner rings don't cut holes
+	polys, _ = Polygons(d, 0.1, NonZero)
+	if len(polys) != 2 || len(polys[0].Holes) != 0 {
+		t.Errorf("Expected 2 polygons without holes with non-zero rule, got %v", polys)
+	}
+
+	// reversing the middle ring makes it a hole under the non-zero rule
+	d = "M0 0 H100 V100 H0 Z M10 10 V90 H90 V10 Z M40 40 H60 V60 H40 Z"
+	polys, _ = Polygons(d, 0.1, NonZero)
+	if len(polys) != 2 || len(polys[0].Holes) != 1 || len(polys[1].Holes) != 0 {
+		t.Errorf("Expected polygon with hole and island with non-zero rule, got %v", polys)
+	}
+}
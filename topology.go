@@ -0,0 +1,147 @@
+package earcut
+
+// HalfEdges is a half-edge view of a triangulation.  Half-edge h belongs
+// to triangle h/3, and runs from vertex triangles[h] to the next vertex of
+// the same triangle, so half-edges are indexed just like the triangle index
+// list.
+type HalfEdges struct {
+	// Triangles is the triangle index list the half-edges were built
+	// from.
+	Triangles []int
+
+	// Twin is the opposite half-edge of each half-edge in the
+	// neighbouring triangle, or -1 if the half-edge is on the boundary.
+	Twin []int
+}
+
+// NewHalfEdges builds the half-edge structure of a triangulation, as
+// returned by Earcut.
+//
+// Half-edges are twins only if their triangles share both vertex indices,
+// so the structure follows the triangulation exactly.  The bridge edges
+// Earcut inserts to link holes to the outer ring connect the triangles on
+// either side, like any other interior edge, since both sides use the same
+// vertices.
+func NewHalfEdges(triangles []int) *HalfEdges {
+	return newHalfEdges(triangles, nil)
+}
+
+// NewWeldedHalfEdges is like NewHalfEdges, but treats vertices with equal x
+// and y coordinates as the same vertex, so that triangles meeting at
+// repeated points, such as where a hole touches the outer ring, join up.
+// Edges of different rings that lie on top of each other are joined too,
+// though the triangulation doesn't connect the triangles there.
+func NewWeldedHalfEdges(data []float64, dim int, triangles []int) *HalfEdges {
+	return newHalfEdges(triangles, weldVertices(data, dim))
+}
+
+// build half-edges, matching vertices through weld, or by index if weld is
+// nil
+func newHalfEdges(triangles []int, weld []int) *HalfEdges {
+	he := &HalfEdges{
+		Triangles: triangles,
+		Twin:      make([]int, len(triangles)),
+	}
+	id := func(v int) int {
+		if weld == nil {
+			return v
+		}
+		return weld[v]
+	}
+	type edge struct {
+		a, b int
+	}
+	open := map[edge][]int{}
+	for h := range triangles {
+		he.Twin[h] = -1
+		a := id(triangles[h])
+		b := id(triangles[he.Next(h)])
+		if a == b {
+			continue
+		}
+		// pair with an unmatched half-edge running the other way
+		back := edge{b, a}
+		if hs := open[back]; len(hs) > 0 {
+			twin := hs[0]
+			open[back] = hs[1:]
+			he.Twin[h] = twin
+			he.Twin[twin] = h
+			continue
+		}
+		e := edge{a, b}
+		open[e] = append(open[e], h)
+	}
+	return he
+}
+
+// map every vertex to the first vertex with the same coordinates
+func weldVertices(data []float64, dim int) []int {
+	n := len(data) / dim
+	weld := make([]int, n)
+	seen := make(map[[2]float64]int, n)
+	for i := 0; i < n; i++ {
+		key := [2]float64{data[i*dim], data[i*dim+1]}
+		if j, ok := seen[key]; ok {
+			weld[i] = j
+		} else {
+			seen[key] = i
+			weld[i] = i
+		}
+	}
+	return weld
+}
+
+// Next returns the next half-edge around the same triangle.
+func (he *HalfEdges) Next(h int) int {
+	if h%3 == 2 {
+		return h - 2
+	}
+	return h + 1
+}
+
+// Prev returns the previous half-edge around the same triangle.
+func (he *HalfEdges) Prev(h int) int {
+	if h%3 == 0 {
+		return h + 2
+	}
+	return h - 1
+}
+
+// Origin returns the vertex a half-edge starts at.
+func (he *HalfEdges) Origin(h int) int {
+	return he.Triangles[h]
+}
+
+// Dest returns the vertex a half-edge ends at.
+func (he *HalfEdges) Dest(h int) int {
+	return he.Triangles[he.Next(h)]
+}
+
+// Boundary returns the half-edges that have no twin.
+func (he *HalfEdges) Boundary() []int {
+	edges := []int{}
+	for h, twin := range he.Twin {
+		if twin < 0 {
+			edges = append(edges, h)
+		}
+	}
+	return edges
+}
+
+// Adjacency returns the neighbouring triangle across each edge of each
+// triangle, or -1 where the edge is on the boundary.  Entry t*3+e is the
+// neighbour across the edge from vertex e to vertex e+1 (mod 3) of
+// triangle t.  Triangles are neighbours only where they share both
+// vertices of an edge, as with NewHalfEdges.
+func Adjacency(triangles []int) []int {
+	he := NewHalfEdges(triangles)
+	adj := make([]int, len(he.Twin))
+	for h, twin := range he.Twin {
+		if twin < 0 {
+			adj[h] = -1
+		} else {
+			adj[h] = twin / 3
+		}
+	}
+	return adj
+}
@@ -0,0 +1,133 @@
+package earcut
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdjacencySquare(t *testing.T) {
+	data := []float64{0, 0, 1, 0, 1, 1, 0, 1}
+	tri, _ := Earcut(data, nil, 2)
+	adj := Adjacency(tri)
+	boundary := 0
+	for h, n := range adj {
+		if n < 0 {
+			boundary++
+		} else if n == h/3 {
+			t.Errorf("Triangle %d adjacent to itself", n)
+		}
+	}
+	if boundary != 4 {
+		t.Errorf("Expected 4 boundary edges, got %d", boundary)
+	}
+}
+
+func TestHalfEdgesBridge(t *testing.T) {
+	// the closing points are repeated, and the hole is linked to the
+	// outer ring by a bridge that must not show up as a boundary
+	data, holes := squareWithHole().flatten()
+	tri, _ := Earcut(data, holes, 2)
+	he := NewHalfEdges(tri)
+	if n := len(he.Boundary()); n != 8 {
+		t.Errorf("Expected 8 boundary edges, got %d", n)
+	}
+}
+
+// check the half-edge invariants, and that the boundary edges add up to
+// the perimeter of the polygon
+func checkHalfEdges(name string, t *testing.T) {
+	flat, holeIndices, err := loadVertices(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tri, _ := Earcut(flat, holeIndices, 2)
+	he := NewHalfEdges(tri)
+	for h, twin := range he.Twin {
+		if twin < 0 {
+			continue
+		}
+		if he.Twin[twin] != h {
+			t.Fatalf("Half-edge %d twin %d not symmetric for %s", h, twin, name)
+		}
+		if he.Origin(h) != he.Dest(twin) || he.Dest(h) != he.Origin(twin) {
+			t.Fatalf("Half-edge %d twin %d endpoints don't match for %s", h, twin, name)
+		}
+	}
+	if he.Prev(he.Next(4)) != 4 || he.Next(he.Next(he.Next(4))) != 4 {
+		t.Error("Next and Prev don't cycle around the triangle")
+	}
+
+	// every boundary edge must lie along an input ring, so no bridge or
+	// diagonal may be left without a twin
+	segs := [][4]float64{}
+	for i, l := 0, len(holeIndices); i <= l; i++ {
+		start, end := ringRange(flat, holeIndices, 2, i)
+		for j, k := start, end-2; j < end; j += 2 {
+			segs = append(segs, [4]float64{flat[k], flat[k+1], flat[j], flat[j+1]})
+			k = j
+		}
+	}
+	for _, h := range he.Boundary() {
+		a, b := he.Origin(h)*2, he.Dest(h)*2
+		mx := (flat[a] + flat[b]) / 2
+		my := (flat[a+1] + flat[b+1]) / 2
+		onRing := false
+		for _, s := range segs {
+			cross := (s[2]-s[0])*(my-s[1]) - (s[3]-s[1])*(mx-s[0])
+			if math.Abs(cross) <= 1e-9*math.Hypot(s[2]-s[0], s[3]-s[1]) &&
+				mx >= math.Min(s[0], s[2]) && mx <= math.Max(s[0], s[2]) &&
+				my >= math.Min(s[1], s[3]) && my <= math.Max(s[1], s[3]) {
+				onRing = true
+				break
+			}
+		}
+		if !onRing {
+			t.Errorf("Boundary half-edge %d not on an input ring for %s", h, name)
+		}
+	}
+}
+
+func TestHalfEdgesFixtures(t *testing.T) {
+	for _, name := range []string{"building", "dude", "water3", "hilbert", "touching-holes", "hole-touching-outer"} {
+		checkHalfEdges(name, t)
+	}
+}
+
+func TestHalfEdgesWelding(t *testing.T) {
+	for _, name := range []string{"hole-touching-outer", "shared-points", "water"} {
+		flat, holeIndices, err := loadVertices(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tri, _ := Earcut(flat, holeIndices, 2)
+		he := NewHalfEdges(tri)
+		welded := NewWeldedHalfEdges(flat, 2, tri)
+		weld := weldVertices(flat, 2)
+		joined := 0
+		for h, twin := range welded.Twin {
+			if twin < 0 {
+				continue
+			}
+			if welded.Twin[twin] != h {
+				t.Fatalf("Welded half-edge %d twin %d not symmetric for %s", h, twin, name)
+			}
+			if weld[he.Origin(h)] != weld[he.Dest(twin)] || weld[he.Dest(h)] != weld[he.Origin(twin)] {
+				t.Fatalf("Welded half-edge %d twin %d endpoints don't match for %s", h, twin, name)
+			}
+			// triangles that only meet at repeated points aren't
+			// neighbours unless welded
+			if he.Twin[h] != twin {
+				if he.Twin[h] >= 0 {
+					t.Fatalf("Half-edge %d has twin %d, welded %d for %s", h, he.Twin[h], twin, name)
+				}
+				joined++
+			}
+		}
+		if name == "hole-touching-outer" && joined != 0 {
+			t.Errorf("Expected no edges joined by welding for %s, got %d", name, joined)
+		}
+		if name != "hole-touching-outer" && joined == 0 {
+			t.Errorf("Expected edges joined by welding for %s", name)
+		}
+	}
+}
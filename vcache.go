@@ -0,0 +1,178 @@
+package earcut
+
+import (
+	"math"
+)
+
+// CacheStats reports the average cache miss ratio (ACMR, the number of
+// vertex cache misses per triangle) of an index buffer before and after
+// optimization.
+type CacheStats struct {
+	CacheSize int
+	Before    float64
+	After     float64
+}
+
+// ACMR simulates a FIFO post-transform vertex cache of the given size, and
+// returns the average number of cache misses per triangle.
+func ACMR(triangles []int, cacheSize int) float64 {
+	n := len(triangles) / 3
+	if n == 0 {
+		return 0.0
+	}
+	fifo := make([]int, 0, cacheSize)
+	inCache := map[int]bool{}
+	misses := 0
+	for _, v := range triangles[:n*3] {
+		if inCache[v] {
+			continue
+		}
+		misses++
+		if len(fifo) == cacheSize {
+			delete(inCache, fifo[0])
+			fifo = fifo[1:]
+		}
+		fifo = append(fifo, v)
+		inCache[v] = true
+	}
+	return float64(misses) / float64(n)
+}
+
+// Tuning constants from Tom Forsyth's "Linear-Speed Vertex Cache
+// Optimisation"
+const (
+	cacheDecayPower   = 1.5
+	lastTriScore      = 0.75
+	valenceBoostScale = 2.0
+	valenceBoostPower = 0.5
+)
+
+// score of a vertex given its position in the simulated cache and the
+// number of triangles still to be emitted that use it
+func vertexScore(cachePos, cacheSize, remaining int) float64 {
+	if remaining == 0 {
+		return -1.0
+	}
+	var score float64
+	if cachePos >= 0 {
+		if cachePos < 3 {
+			// the vertices of the last triangle are scored
+			// lower, so that strips don't keep doubling back
+			score = lastTriScore
+		} else {
+			scale := 1.0 / float64(cacheSize-3)
+			score = math.Pow(1.0-float64(cachePos-3)*scale, cacheDecayPower)
+		}
+	}
+	return score + valenceBoostScale*math.Pow(float64(remaining), -valenceBoostPower)
+}
+
+// OptimizeVertexCache reorders the triangles of a triangle list to improve
+// post-transform vertex cache hit rates, using Tom Forsyth's linear-speed
+// algorithm with a simulated LRU cache of the given size.  The triangle set
+// and the winding of each triangle are unchanged; only the order of the
+// triangles is.  The ACMR of the list, measured with a FIFO cache of the
+// same size, is reported before and after.
+func OptimizeVertexCache(triangles []int, cacheSize int) ([]int, CacheStats) {
+	if cacheSize < 4 {
+		cacheSize = 4
+	}
+	stats := CacheStats{CacheSize: cacheSize, Before: ACMR(triangles, cacheSize)}
+	n := len(triangles) / 3
+	nv := 0
+	for _, v := range triangles[:n*3] {
+		if v+1 > nv {
+			nv = v + 1
+		}
+	}
+
+	type vertex struct {
+		cachePos int
+		score    float64
+		tris     []int
+	}
+	verts := make([]vertex, nv)
+	for t := 0; t < n; t++ {
+		for _, v := range triangles[t*3 : t*3+3] {
+			verts[v].tris = append(verts[v].tris, t)
+		}
+	}
+	for v := range verts {
+		verts[v].cachePos = -1
+		verts[v].score = vertexScore(-1, cacheSize, len(verts[v].tris))
+	}
+	added := make([]bool, n)
+	triScore := make([]float64, n)
+	best := -1
+	for t := 0; t < n; t++ {
+		for _, v := range triangles[t*3 : t*3+3] {
+			triScore[t] += verts[v].score
+		}
+		if best < 0 || triScore[t] > triScore[best] {
+			best = t
+		}
+	}
+
+	out := make([]int, 0, n*3)
+	cache := make([]int, 0, cacheSize+3)
+	next := make([]int, 0, cacheSize+3)
+	for emitted := 0; emitted < n; emitted++ {
+		if best < 0 {
+			// nothing in the cache is usable; find the best
+			// remaining triangle anywhere
+			for t := 0; t < n; t++ {
+				if !added[t] && (best < 0 || triScore[t] > triScore[best]) {
+					best = t
+				}
+			}
+		}
+		tri := triangles[best*3 : best*3+3]
+		out = append(out, tri...)
+		added[best] = true
+		for _, v := range tri {
+			tris := verts[v].tris
+			for i, t := range tris {
+				if t == best {
+					verts[v].tris = append(tris[:i], tris[i+1:]...)
+					break
+				}
+			}
+		}
+
+		// move the triangle's vertices to the front of the cache
+		next = next[:0]
+		next = append(next, tri...)
+		for _, v := range cache {
+			if v != tri[0] && v != tri[1] && v != tri[2] {
+				next = append(next, v)
+			}
+		}
+		for i, v := range next {
+			if i < cacheSize {
+				verts[v].cachePos = i
+			} else {
+				verts[v].cachePos = -1
+			}
+			verts[v].score = vertexScore(verts[v].cachePos, cacheSize, len(verts[v].tris))
+		}
+
+		// rescore the triangles using any vertex that was in the cache,
+		// and pick the best of them to emit next
+		best = -1
+		for _, v := range next {
+			for _, t := range verts[v].tris {
+				a, b, c := triangles[t*3], triangles[t*3+1], triangles[t*3+2]
+				triScore[t] = verts[a].score + verts[b].score + verts[c].score
+				if best < 0 || triScore[t] > triScore[best] {
+					best = t
+				}
+			}
+		}
+		if len(next) > cacheSize {
+			next = next[:cacheSize]
+		}
+		cache, next = next, cache
+	}
+	stats.After = ACMR(out, cacheSize)
+	return out, stats
+}
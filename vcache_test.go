@@ -0,0 +1,48 @@
+package earcut
+
+import (
+	"testing"
+)
+
+func TestACMR(t *testing.T) {
+	// two triangles sharing an edge need 4 vertex loads
+	if acmr := ACMR([]int{0, 1, 2, 2, 1, 3}, 16); acmr != 2.0 {
+		t.Errorf("Expected ACMR 2, got %f", acmr)
+	}
+	// with a cache of 3, the shared vertices are evicted
+	if acmr := ACMR([]int{0, 1, 2, 3, 4, 5, 0, 1, 2}, 3); acmr != 3.0 {
+		t.Errorf("Expected ACMR 3, got %f", acmr)
+	}
+	if acmr := ACMR(nil, 16); acmr != 0.0 {
+		t.Errorf("Expected ACMR 0, got %f", acmr)
+	}
+}
+
+func TestOptimizeVertexCacheFixtures(t *testing.T) {
+	for _, name := range []string{"dude", "water", "water-huge", "hilbert", "eberly-6"} {
+		flat, holeIndices, err := loadVertices(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tri, _ := Earcut(flat, holeIndices, 2)
+		out, stats := OptimizeVertexCache(tri, 32)
+		if stats.Before != ACMR(tri, 32) || stats.After != ACMR(out, 32) {
+			t.Errorf("Reported ACMR doesn't match for %s", name)
+		}
+		if stats.After > stats.Before {
+			t.Errorf("ACMR got worse for %s: %f -> %f", name, stats.Before, stats.After)
+		}
+		exp := normalizeTriangles(tri)
+		got := normalizeTriangles(out)
+		if len(exp) != len(got) {
+			t.Fatalf("Optimized list has %d triangles, expected %d for %s", len(got), len(exp), name)
+		}
+		for i := range exp {
+			if exp[i] != got[i] {
+				t.Errorf("Triangles don't match for %s: %v != %v", name, got[i], exp[i])
+				break
+			}
+		}
+		t.Logf("%s: ACMR %.3f -> %.3f", name, stats.Before, stats.After)
+	}
+}
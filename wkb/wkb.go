@@ -0,0 +1,358 @@
+// Package wkb decodes Well-Known Binary and PostGIS Extended WKB polygons
+// into the flat vertex arrays consumed by earcut, and encodes earcut
+// triangulations back into WKB.
+package wkb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// Geometry type codes
+const (
+	TypePolygon      = 3
+	TypeMultiPolygon = 6
+	TypeTIN          = 16
+	TypeTriangle     = 17
+)
+
+// EWKB type flags
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// TruncatedError is returned when the input ends in the middle of a
+// geometry.
+type TruncatedError struct {
+	// Offset is the position in the input of the value that was cut
+	// short.
+	Offset int
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("wkb: truncated geometry at offset %d", e.Offset)
+}
+
+// UnsupportedTypeError is returned for geometry types that can't be
+// triangulated, or for type codes that aren't valid.
+type UnsupportedTypeError struct {
+	Type uint32
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("wkb: unsupported geometry type %d", e.Type)
+}
+
+// Geometry is a decoded Polygon or MultiPolygon.  Triangle and TIN
+// geometries are decoded as if they were a Polygon and a MultiPolygon.
+type Geometry struct {
+	// SRID is the spatial reference id from an EWKB header, or 0 if
+	// there was none.
+	SRID int
+
+	earcut.Geometry
+}
+
+// Decode decodes a WKB or EWKB Polygon or MultiPolygon.  Both byte orders,
+// the EWKB Z, M and SRID flags, and the ISO Z, M and ZM type codes are
+// supported.
+func Decode(b []byte) (*Geometry, error) {
+	d := &decoder{buf: b}
+	g := &Geometry{
+		Geometry: earcut.Geometry{
+			Data:     []float64{},
+			Polygons: []earcut.Part{},
+		},
+	}
+	typ, err := d.header(g, true)
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case TypePolygon, TypeTriangle:
+		err = d.polygon(g)
+	case TypeMultiPolygon, TypeTIN:
+		err = d.multiPolygon(g, typ)
+	default:
+		err = &UnsupportedTypeError{Type: typ}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(b) {
+		return nil, fmt.Errorf("wkb: %d unexpected bytes after geometry", len(b)-d.pos)
+	}
+	return g, nil
+}
+
+type decoder struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+func (d *decoder) need(n int) error {
+	if n < 0 || len(d.buf)-d.pos < n {
+		return &TruncatedError{Offset: d.pos}
+	}
+	return nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if err := d.need(4); err != nil {
+		return 0, err
+	}
+	v := d.order.Uint32(d.buf[d.pos:])
+	d.pos += 4
+	return v, nil
+}
+
+// read the byte order and geometry type of a geometry header, returning
+// the base type code; the dimensions of the top level geometry are stored
+// in g, and nested geometries must match them
+func (d *decoder) header(g *Geometry, top bool) (uint32, error) {
+	if err := d.need(1); err != nil {
+		return 0, err
+	}
+	switch d.buf[d.pos] {
+	case 0:
+		d.order = binary.BigEndian
+	case 1:
+		d.order = binary.LittleEndian
+	default:
+		return 0, fmt.Errorf("wkb: invalid byte order %d at offset %d", d.buf[d.pos], d.pos)
+	}
+	d.pos++
+	raw, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	hasZ := raw&ewkbZ != 0
+	hasM := raw&ewkbM != 0
+	if raw&ewkbSRID != 0 {
+		if !top {
+			return 0, fmt.Errorf("wkb: unexpected SRID in nested geometry at offset %d", d.pos-4)
+		}
+		srid, err := d.uint32()
+		if err != nil {
+			return 0, err
+		}
+		g.SRID = int(int32(srid))
+	}
+	typ := raw &^ (ewkbZ | ewkbM | ewkbSRID)
+	switch typ / 1000 {
+	case 0:
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ = true
+		hasM = true
+	default:
+		return 0, &UnsupportedTypeError{Type: raw}
+	}
+	typ %= 1000
+	if top {
+		g.HasZ = hasZ
+		g.HasM = hasM
+		g.Dim = 2
+		if hasZ {
+			g.Dim++
+		}
+		if hasM {
+			g.Dim++
+		}
+	} else if hasZ != g.HasZ || hasM != g.HasM {
+		return 0, fmt.Errorf("wkb: mixed dimensions in nested geometry at offset %d", d.pos-4)
+	}
+	return typ, nil
+}
+
+func (d *decoder) multiPolygon(g *Geometry, typ uint32) error {
+	n, err := d.uint32()
+	if err != nil {
+		return err
+	}
+	member := uint32(TypePolygon)
+	if typ == TypeTIN {
+		member = TypeTriangle
+	}
+	for i := uint32(0); i < n; i++ {
+		t, err := d.header(g, false)
+		if err != nil {
+			return err
+		}
+		if t != member {
+			return &UnsupportedTypeError{Type: t}
+		}
+		if err = d.polygon(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) polygon(g *Geometry) error {
+	nrings, err := d.uint32()
+	if err != nil {
+		return err
+	}
+	start := len(g.Data) / g.Dim
+	part := earcut.Part{Start: start}
+	for i := uint32(0); i < nrings; i++ {
+		npoints, err := d.uint32()
+		if err != nil {
+			return err
+		}
+		if err = d.need(int(npoints) * g.Dim * 8); err != nil {
+			return err
+		}
+		if i > 0 && npoints > 0 {
+			part.Holes = append(part.Holes, len(g.Data)/g.Dim)
+		}
+		for j := 0; j < int(npoints)*g.Dim; j++ {
+			g.Data = append(g.Data, math.Float64frombits(d.order.Uint64(d.buf[d.pos:])))
+			d.pos += 8
+		}
+	}
+	part.End = len(g.Data) / g.Dim
+	g.Polygons = append(g.Polygons, part)
+	return nil
+}
+
+// EncodeOptions controls how triangulations are encoded.
+type EncodeOptions struct {
+	// ByteOrder defaults to little endian.
+	ByteOrder binary.ByteOrder
+
+	// SRID, if non-zero, is written in an EWKB header, and the EWKB
+	// dimension flags are used in place of the ISO type codes.
+	SRID int
+
+	// HasZ and HasM say which extra ordinates the vertex data carries,
+	// in x, y, z, m order.  If neither is set, they are inferred from
+	// dim: a third value is Z and a fourth is M.
+	HasZ bool
+	HasM bool
+}
+
+// EncodeTIN encodes a triangulation as a TIN of Triangle geometries.
+// Vertices are read from data with dim values per vertex, and triangles
+// holds 3 vertex indices per triangle, as returned by earcut.Earcut.
+func EncodeTIN(data []float64, dim int, triangles []int, opts *EncodeOptions) ([]byte, error) {
+	return encode(data, dim, triangles, opts, TypeTIN, TypeTriangle)
+}
+
+// EncodeMultiPolygon encodes a triangulation as a MultiPolygon with one
+// triangular Polygon per triangle.
+func EncodeMultiPolygon(data []float64, dim int, triangles []int, opts *EncodeOptions) ([]byte, error) {
+	return encode(data, dim, triangles, opts, TypeMultiPolygon, TypePolygon)
+}
+
+type encoder struct {
+	buf   []byte
+	order binary.ByteOrder
+	srid  int
+	hasZ  bool
+	hasM  bool
+}
+
+func encode(data []float64, dim int, triangles []int, opts *EncodeOptions, typ, member uint32) ([]byte, error) {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	e := &encoder{
+		order: opts.ByteOrder,
+		srid:  opts.SRID,
+		hasZ:  opts.HasZ,
+		hasM:  opts.HasM,
+	}
+	if e.order == nil {
+		e.order = binary.LittleEndian
+	}
+	if !e.hasZ && !e.hasM {
+		e.hasZ = dim > 2
+		e.hasM = dim > 3
+	}
+	vals := 2
+	if e.hasZ {
+		vals++
+	}
+	if e.hasM {
+		vals++
+	}
+	if dim < vals {
+		return nil, errors.New("wkb: not enough dimensions for Z and M values")
+	}
+	if len(triangles)%3 != 0 {
+		return nil, errors.New("wkb: triangle index count is not a multiple of 3")
+	}
+	for _, i := range triangles {
+		if i < 0 || (i+1)*dim > len(data) {
+			return nil, fmt.Errorf("wkb: vertex index %d out of range", i)
+		}
+	}
+	n := len(triangles) / 3
+	e.buf = make([]byte, 0, 13+n*(13+4*vals*8))
+	e.header(typ, true)
+	e.uint32(uint32(n))
+	for t := 0; t < len(triangles); t += 3 {
+		e.header(member, false)
+		e.uint32(1)
+		e.uint32(4)
+		for _, i := range [4]int{triangles[t], triangles[t+1], triangles[t+2], triangles[t]} {
+			for _, v := range data[i*dim : i*dim+vals] {
+				e.uint64(math.Float64bits(v))
+			}
+		}
+	}
+	return e.buf, nil
+}
+
+func (e *encoder) header(typ uint32, top bool) {
+	if e.order == binary.BigEndian {
+		e.buf = append(e.buf, 0)
+	} else {
+		e.buf = append(e.buf, 1)
+	}
+	if e.srid != 0 {
+		if e.hasZ {
+			typ |= ewkbZ
+		}
+		if e.hasM {
+			typ |= ewkbM
+		}
+		if top {
+			e.uint32(typ | ewkbSRID)
+			e.uint32(uint32(int32(e.srid)))
+			return
+		}
+	} else {
+		if e.hasZ {
+			typ += 1000
+		}
+		if e.hasM {
+			typ += 2000
+		}
+	}
+	e.uint32(typ)
+}
+
+func (e *encoder) uint32(v uint32) {
+	var b [4]byte
+	e.order.PutUint32(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) uint64(v uint64) {
+	var b [8]byte
+	e.order.PutUint64(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
@@ -0,0 +1,239 @@
+package wkb
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+var epsilon = float64(1.0e-12)
+
+// build a WKB polygon by hand
+func polygonWKB(order binary.ByteOrder, typ uint32, srid *uint32, rings ...[]float64) []byte {
+	b := []byte{1}
+	if order == binary.BigEndian {
+		b[0] = 0
+	}
+	put32 := func(v uint32) {
+		var tmp [4]byte
+		order.PutUint32(tmp[:], v)
+		b = append(b, tmp[:]...)
+	}
+	put32(typ)
+	if srid != nil {
+		put32(*srid)
+	}
+	put32(uint32(len(rings)))
+	for _, ring := range rings {
+		dim := 2
+		if typ&(ewkbZ|ewkbM) != 0 || typ%1000 != typ {
+			dim = 3
+		}
+		put32(uint32(len(ring) / dim))
+		for _, v := range ring {
+			var tmp [8]byte
+			order.PutUint64(tmp[:], math.Float64bits(v))
+			b = append(b, tmp[:]...)
+		}
+	}
+	return b
+}
+
+var outer2D = []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0}
+var hole2D = []float64{1, 1, 1, 2, 2, 2, 2, 1, 1, 1}
+
+func TestDecodePolygon(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		g, err := Decode(polygonWKB(order, TypePolygon, nil, outer2D, hole2D))
+		if err != nil {
+			t.Errorf("Error decoding %s polygon: %s", order, err)
+			continue
+		}
+		if g.Dim != 2 || len(g.Data) != 20 {
+			t.Errorf("Expected 10 vertices of dim 2, got %d of dim %d", len(g.Data)/g.Dim, g.Dim)
+		}
+		data, holes := g.Polygon(0)
+		if len(holes) != 1 || holes[0] != 5 {
+			t.Error("Hole indices don't match", holes)
+		}
+		tri, err := earcut.Earcut(data, holes, g.Dim)
+		if err != nil {
+			t.Error("Error in earcut:", err)
+		}
+		if d := earcut.Deviation(data, holes, g.Dim, tri); d > epsilon {
+			t.Errorf("Triangle area not equal to polygon area (%.6f%% deviation", d*100.0)
+		}
+	}
+}
+
+func TestDecodeDimensions(t *testing.T) {
+	ring := []float64{0, 0, 1, 4, 0, 1, 4, 4, 1, 0, 0, 1}
+	srid := uint32(4326)
+	tests := []struct {
+		b    []byte
+		hasZ bool
+		hasM bool
+		srid int
+	}{
+		{polygonWKB(binary.LittleEndian, TypePolygon+1000, nil, ring), true, false, 0},
+		{polygonWKB(binary.LittleEndian, TypePolygon+2000, nil, ring), false, true, 0},
+		{polygonWKB(binary.BigEndian, TypePolygon|ewkbZ, nil, ring), true, false, 0},
+		{polygonWKB(binary.LittleEndian, TypePolygon|ewkbM|ewkbSRID, &srid, ring), false, true, 4326},
+	}
+	for i, test := range tests {
+		g, err := Decode(test.b)
+		if err != nil {
+			t.Errorf("Error decoding geometry %d: %s", i, err)
+			continue
+		}
+		if g.Dim != 3 || g.HasZ != test.hasZ || g.HasM != test.hasM || g.SRID != test.srid {
+			t.Errorf("Header doesn't match for geometry %d: %+v", i, g)
+		}
+		if len(g.Data) != 12 || g.Data[2] != 1 {
+			t.Errorf("Vertex data doesn't match for geometry %d: %v", i, g.Data)
+		}
+	}
+}
+
+func TestDecodeMultiPolygon(t *testing.T) {
+	b := []byte{1, 6, 0, 0, 0, 2, 0, 0, 0}
+	b = append(b, polygonWKB(binary.LittleEndian, TypePolygon, nil, outer2D, hole2D)...)
+	b = append(b, polygonWKB(binary.BigEndian, TypePolygon, nil, []float64{10, 0, 12, 0, 12, 2, 10, 0})...)
+	g, err := Decode(b)
+	if err != nil {
+		t.Fatal("Error decoding multipolygon:", err)
+	}
+	if len(g.Polygons) != 2 {
+		t.Fatalf("Expected 2 polygons, got %d", len(g.Polygons))
+	}
+	if p := g.Polygons[1]; p.Start != 10 || p.End != 14 || len(p.Holes) != 0 {
+		t.Error("Second polygon doesn't match", p)
+	}
+	tri, ranges, err := g.Triangulate()
+	if err != nil {
+		t.Error("Error triangulating multipolygon:", err)
+	}
+	if len(tri) != 27 || len(ranges) != 2 {
+		t.Errorf("Expected 27 indices in 2 ranges, got %d in %d", len(tri), len(ranges))
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	full := polygonWKB(binary.LittleEndian, TypePolygon, nil, outer2D)
+	// the offset is where the read that ran out started: the byte order,
+	// the type, the point count of the ring, then its points
+	for n, off := range map[int]int{0: 0, 1: 1, 4: 1, 9: 9, 12: 9, len(full) - 1: 13} {
+		_, err := Decode(full[:n])
+		if terr, ok := err.(*TruncatedError); !ok {
+			t.Errorf("Expected *TruncatedError for %d bytes, got %v", n, err)
+		} else if terr.Offset != off {
+			t.Errorf("Expected offset %d for %d bytes, got %d", off, n, terr.Offset)
+		}
+	}
+	point := []byte{1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	if _, err := Decode(point); err == nil {
+		t.Error("Expected error decoding point")
+	} else if terr, ok := err.(*UnsupportedTypeError); !ok || terr.Type != 1 {
+		t.Errorf("Expected *UnsupportedTypeError decoding point, got %v", err)
+	}
+	bad := []byte{1, 6, 0, 0, 0, 1, 0, 0, 0}
+	bad = append(bad, point...)
+	if _, err := Decode(bad); err == nil {
+		t.Error("Expected error decoding multipolygon containing a point")
+	}
+	if _, err := Decode(append(full, 0)); err == nil {
+		t.Error("Expected error for trailing bytes")
+	}
+	if _, err := Decode([]byte{2, 3, 0, 0, 0}); err == nil {
+		t.Error("Expected error for invalid byte order")
+	}
+
+	// holes with nothing around them
+	for _, outer := range [][]float64{{}, {0, 0, 4, 0, 0, 0}} {
+		b := []byte{1, 6, 0, 0, 0, 2, 0, 0, 0}
+		b = append(b, full...)
+		b = append(b, polygonWKB(binary.LittleEndian, TypePolygon, nil, outer, hole2D)...)
+		g, err := Decode(b)
+		if err != nil {
+			t.Errorf("Error decoding %v: %s", outer, err)
+			continue
+		}
+		if _, _, err = g.Triangulate(); err == nil || !strings.HasPrefix(err.Error(), "part 1: ") {
+			t.Errorf("Expected error naming part 1 for %v, got %v", outer, err)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	data := []float64{
+		0, 0, 5,
+		4, 0, 6,
+		4, 4, 7,
+		0, 4, 8,
+	}
+	tri, err := earcut.Earcut(data, nil, 3)
+	if err != nil {
+		t.Fatal("Error in earcut:", err)
+	}
+	opts := []*EncodeOptions{
+		nil,
+		{ByteOrder: binary.BigEndian},
+		{SRID: 3857},
+		{HasM: true},
+	}
+	for _, enc := range []func([]float64, int, []int, *EncodeOptions) ([]byte, error){EncodeTIN, EncodeMultiPolygon} {
+		for _, opt := range opts {
+			b, err := enc(data, 3, tri, opt)
+			if err != nil {
+				t.Error("Error encoding triangles:", err)
+				continue
+			}
+			g, err := Decode(b)
+			if err != nil {
+				t.Error("Error decoding triangles:", err)
+				continue
+			}
+			if len(g.Polygons) != 2 || g.Dim != 3 {
+				t.Errorf("Expected 2 polygons of dim 3, got %d of dim %d", len(g.Polygons), g.Dim)
+				continue
+			}
+			if opt != nil && opt.SRID != g.SRID {
+				t.Errorf("Expected SRID %d, got %d", opt.SRID, g.SRID)
+			}
+			if opt != nil && opt.HasM && (g.HasZ || !g.HasM) {
+				t.Error("Expected M values")
+			}
+			for i := 0; i < 2; i++ {
+				p := g.Polygons[i]
+				if p.End-p.Start != 4 {
+					t.Errorf("Expected 4 points in triangle %d, got %d", i, p.End-p.Start)
+					continue
+				}
+				for j := 0; j < 4; j++ {
+					v := tri[i*3+j%3]
+					for k := 0; k < 3; k++ {
+						if g.Data[(p.Start+j)*3+k] != data[v*3+k] {
+							t.Errorf("Vertex %d of triangle %d doesn't match", j, i)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	data := []float64{0, 0, 1, 0, 0, 1}
+	if _, err := EncodeTIN(data, 2, []int{0, 1}, nil); err == nil {
+		t.Error("Expected error for partial triangle")
+	}
+	if _, err := EncodeTIN(data, 2, []int{0, 1, 3}, nil); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+	if _, err := EncodeTIN(data, 2, []int{0, 1, 2}, &EncodeOptions{HasZ: true}); err == nil {
+		t.Error("Expected error for missing Z values")
+	}
+}
@@ -0,0 +1,382 @@
+// Package wkt parses Well-Known Text polygons into the flat vertex arrays
+// consumed by earcut.
+package wkt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rclancey/go-earcut"
+)
+
+// SyntaxError describes a problem with the WKT input and where it occurred.
+// Lines and columns are 1-based, and columns count characters rather than
+// bytes.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("wkt: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Geometry is a parsed POLYGON or MULTIPOLYGON.  A POLYGON has exactly one
+// entry in Polygons, unless it is EMPTY.
+type Geometry struct {
+	// SRID is the spatial reference id of an EWKT "SRID=n;" prefix, or 0
+	// if there was none.
+	SRID int
+
+	earcut.Geometry
+}
+
+// Parse parses a POLYGON or MULTIPOLYGON in Well-Known Text, including the
+// Z, M and ZM variants and an optional EWKT SRID prefix.  Keywords are case
+// insensitive.
+func Parse(s string) (*Geometry, error) {
+	p := &parser{lex: lexer{src: s, line: 1, col: 1}}
+	p.next()
+	g, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokInvalid
+	tokWord
+	tokNumber
+	tokLParen
+	tokRParen
+	tokComma
+	tokSemicolon
+	tokEquals
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	col  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+// advance moves past the character at pos, which may take several bytes
+func (l *lexer) advance() {
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	l.pos += size
+}
+
+func (l *lexer) scan() token {
+	for l.pos < len(l.src) {
+		if r, _ := utf8.DecodeRuneInString(l.src[l.pos:]); !unicode.IsSpace(r) {
+			break
+		}
+		l.advance()
+	}
+	tok := token{line: l.line, col: l.col}
+	if l.pos >= len(l.src) {
+		tok.kind = tokEOF
+		return tok
+	}
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case c == '(':
+		tok.kind = tokLParen
+		l.advance()
+	case c == ')':
+		tok.kind = tokRParen
+		l.advance()
+	case c == ',':
+		tok.kind = tokComma
+		l.advance()
+	case c == ';':
+		tok.kind = tokSemicolon
+		l.advance()
+	case c == '=':
+		tok.kind = tokEquals
+		l.advance()
+	case isLetter(c):
+		tok.kind = tokWord
+		for l.pos < len(l.src) && isLetter(l.src[l.pos]) {
+			l.advance()
+		}
+	case isDigit(c) || c == '-' || c == '+' || c == '.':
+		tok.kind = tokNumber
+		for l.pos < len(l.src) && isNumberChar(l.src[l.pos]) {
+			l.advance()
+		}
+	default:
+		tok.kind = tokInvalid
+		l.advance()
+	}
+	tok.text = l.src[start:l.pos]
+	return tok
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNumberChar(c byte) bool {
+	return isDigit(c) || c == '.' || c == '-' || c == '+' || c == 'e' || c == 'E'
+}
+
+type parser struct {
+	lex  lexer
+	tok  token
+	g    *Geometry
+	vals int
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.scan()
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{
+		Line:   p.tok.line,
+		Column: p.tok.col,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *parser) expect(kind tokenKind, what string) error {
+	if p.tok.kind != kind {
+		return p.errorf("expected %s, found %s", what, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parse() (*Geometry, error) {
+	p.g = &Geometry{}
+	if p.tok.kind == tokWord && strings.EqualFold(p.tok.text, "SRID") {
+		if err := p.parseSRID(); err != nil {
+			return nil, err
+		}
+	}
+	if p.tok.kind != tokWord {
+		return nil, p.errorf("expected geometry type, found %s", p.tok)
+	}
+	kw := strings.ToUpper(p.tok.text)
+	multi := false
+	var suffix string
+	switch {
+	case strings.HasPrefix(kw, "MULTIPOLYGON"):
+		multi = true
+		suffix = kw[len("MULTIPOLYGON"):]
+	case strings.HasPrefix(kw, "POLYGON"):
+		suffix = kw[len("POLYGON"):]
+	default:
+		return nil, p.errorf("unsupported geometry type %s", p.tok)
+	}
+	// the Z, M or ZM tag may be attached, as PostGIS writes POLYGONM,
+	// or a separate word
+	if suffix != "" && !p.setDimensions(suffix) {
+		return nil, p.errorf("unsupported geometry type %s", p.tok)
+	}
+	p.next()
+	if suffix == "" && p.tok.kind == tokWord && p.setDimensions(strings.ToUpper(p.tok.text)) {
+		p.next()
+	}
+	if p.g.HasZ || p.g.HasM {
+		p.vals = p.g.Dim
+	}
+	if p.tok.kind == tokWord && strings.EqualFold(p.tok.text, "EMPTY") {
+		p.next()
+	} else {
+		var err error
+		if multi {
+			err = p.parseMultiPolygon()
+		} else {
+			err = p.parsePolygon()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s after geometry", p.tok)
+	}
+	if p.g.Dim == 0 {
+		p.g.Dim = 2
+	}
+	if p.g.Data == nil {
+		p.g.Data = []float64{}
+	}
+	if p.g.Polygons == nil {
+		p.g.Polygons = []earcut.Part{}
+	}
+	return p.g, nil
+}
+
+// parse an EWKT "SRID=n;" prefix
+func (p *parser) parseSRID() error {
+	p.next()
+	if err := p.expect(tokEquals, "'='"); err != nil {
+		return err
+	}
+	if p.tok.kind != tokNumber {
+		return p.errorf("expected SRID, found %s", p.tok)
+	}
+	srid, err := strconv.Atoi(p.tok.text)
+	if err != nil {
+		return p.errorf("invalid SRID %s", p.tok)
+	}
+	p.g.SRID = srid
+	p.next()
+	return p.expect(tokSemicolon, "';'")
+}
+
+// set the dimensions from a Z, M or ZM tag
+func (p *parser) setDimensions(tag string) bool {
+	switch tag {
+	case "Z":
+		p.g.HasZ = true
+	case "M":
+		p.g.HasM = true
+	case "ZM":
+		p.g.HasZ = true
+		p.g.HasM = true
+	default:
+		return false
+	}
+	p.g.Dim = 2
+	if p.g.HasZ {
+		p.g.Dim++
+	}
+	if p.g.HasM {
+		p.g.Dim++
+	}
+	return true
+}
+
+func (p *parser) parseMultiPolygon() error {
+	if err := p.expect(tokLParen, "'('"); err != nil {
+		return err
+	}
+	for {
+		if p.tok.kind == tokWord && strings.EqualFold(p.tok.text, "EMPTY") {
+			p.next()
+		} else if err := p.parsePolygon(); err != nil {
+			return err
+		}
+		if p.tok.kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	return p.expect(tokRParen, "',' or ')'")
+}
+
+func (p *parser) parsePolygon() error {
+	if err := p.expect(tokLParen, "'('"); err != nil {
+		return err
+	}
+	start := p.vertexCount()
+	part := earcut.Part{Start: start}
+	for {
+		if p.vertexCount() > start {
+			part.Holes = append(part.Holes, p.vertexCount())
+		}
+		if err := p.parseRing(); err != nil {
+			return err
+		}
+		if p.tok.kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	part.End = p.vertexCount()
+	p.g.Polygons = append(p.g.Polygons, part)
+	return p.expect(tokRParen, "',' or ')'")
+}
+
+func (p *parser) parseRing() error {
+	if err := p.expect(tokLParen, "'('"); err != nil {
+		return err
+	}
+	for {
+		if err := p.parsePoint(); err != nil {
+			return err
+		}
+		if p.tok.kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	return p.expect(tokRParen, "',' or ')'")
+}
+
+func (p *parser) parsePoint() error {
+	line, col := p.tok.line, p.tok.col
+	n := 0
+	for p.tok.kind == tokNumber {
+		v, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return p.errorf("invalid number %s", p.tok)
+		}
+		p.g.Data = append(p.g.Data, v)
+		n++
+		p.next()
+	}
+	if n == 0 {
+		return p.errorf("expected coordinate, found %s", p.tok)
+	}
+	if p.vals == 0 {
+		// no dimension tag, so the first point decides; a third
+		// value is Z, and a fourth is M
+		if n < 2 || n > 4 {
+			return &SyntaxError{line, col, fmt.Sprintf("point has %d coordinates", n)}
+		}
+		p.vals = n
+		p.g.Dim = n
+		p.g.HasZ = n > 2
+		p.g.HasM = n > 3
+	}
+	if n != p.vals {
+		return &SyntaxError{line, col, fmt.Sprintf("point has %d coordinates, expected %d", n, p.vals)}
+	}
+	return nil
+}
+
+func (p *parser) vertexCount() int {
+	if p.vals == 0 {
+		return 0
+	}
+	return len(p.g.Data) / p.vals
+}
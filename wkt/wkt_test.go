@@ -0,0 +1,164 @@
+package wkt
+
+import (
+	"testing"
+
+	"github.com/rclancey/go-earcut"
+)
+
+var epsilon = float64(1.0e-12)
+
+func TestParsePolygon(t *testing.T) {
+	g, err := Parse("POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 1 2, 2 2, 2 1, 1 1))")
+	if err != nil {
+		t.Fatal("Error parsing polygon:", err)
+	}
+	if g.Dim != 2 || g.HasZ || g.HasM {
+		t.Errorf("Expected 2 dimensions, got %d", g.Dim)
+	}
+	if len(g.Data) != 20 {
+		t.Errorf("Expected 20 values, got %d", len(g.Data))
+	}
+	data, holes := g.Polygon(0)
+	if len(holes) != 1 || holes[0] != 5 {
+		t.Error("Hole indices don't match", holes)
+	}
+	tri, err := earcut.Earcut(data, holes, g.Dim)
+	if err != nil {
+		t.Error("Error in earcut:", err)
+	}
+	if len(tri) != 24 {
+		t.Errorf("Expected 24 vertex indices, got %d", len(tri))
+	}
+	if d := earcut.Deviation(data, holes, g.Dim, tri); d > epsilon {
+		t.Errorf("Triangle area not equal to polygon area (%.6f%% deviation", d*100.0)
+	}
+}
+
+func TestParseDimensions(t *testing.T) {
+	tests := []struct {
+		src  string
+		dim  int
+		hasZ bool
+		hasM bool
+	}{
+		{"POLYGON Z ((0 0 1, 1 0 1, 0 1 1, 0 0 1))", 3, true, false},
+		{"polygon m ((0 0 1, 1 0 1, 0 1 1, 0 0 1))", 3, false, true},
+		{"POLYGON ZM ((0 0 1 2, 1 0 1 2, 0 1 1 2, 0 0 1 2))", 4, true, true},
+		{"POLYGONZ ((0 0 1, 1 0 1, 0 1 1, 0 0 1))", 3, true, false},
+		{"POLYGONM((0 0 1, 1 0 1, 0 1 1, 0 0 1))", 3, false, true},
+		{"SRID=4326;POLYGONM((0 0 1, 1 0 1, 0 1 1, 0 0 1))", 3, false, true},
+		{"MULTIPOLYGONM(((0 0 1, 1 0 1, 0 1 1, 0 0 1)))", 3, false, true},
+		{"multipolygonzm (((0 0 1 2, 1 0 1 2, 0 1 1 2, 0 0 1 2)))", 4, true, true},
+		{"POLYGON ((0 0 1, 1 0 1, 0 1 1, 0 0 1))", 3, true, false},
+		{"POLYGON ((0 0 1 2, 1 0 1 2, 0 1 1 2, 0 0 1 2))", 4, true, true},
+		{"SRID=4326;POLYGON ((0 0, 1 0, 0 1, 0 0))", 2, false, false},
+	}
+	for _, test := range tests {
+		g, err := Parse(test.src)
+		if err != nil {
+			t.Errorf("Error parsing %s: %s", test.src, err)
+			continue
+		}
+		if g.Dim != test.dim || g.HasZ != test.hasZ || g.HasM != test.hasM {
+			t.Errorf("Dimensions don't match for %s: %d %t %t", test.src, g.Dim, g.HasZ, g.HasM)
+		}
+		if len(g.Data) != 4*test.dim {
+			t.Errorf("Expected %d values for %s, got %d", 4*test.dim, test.src, len(g.Data))
+		}
+	}
+	g, _ := Parse("SRID=4326;POLYGON ((0 0, 1 0, 0 1, 0 0))")
+	if g.SRID != 4326 {
+		t.Errorf("Expected SRID 4326, got %d", g.SRID)
+	}
+}
+
+func TestParseMultiPolygon(t *testing.T) {
+	src := `MULTIPOLYGON Z (
+		((0 0 5, 4 0 5, 4 4 5, 0 4 5, 0 0 5), (1 1 6, 1 2 6, 2 2 6, 2 1 6, 1 1 6)),
+		EMPTY,
+		((10 0 7, 12 0 7, 12 2 7, 10 2 7, 10 0 7))
+	)`
+	g, err := Parse(src)
+	if err != nil {
+		t.Fatal("Error parsing multipolygon:", err)
+	}
+	if len(g.Polygons) != 2 {
+		t.Fatalf("Expected 2 polygons, got %d", len(g.Polygons))
+	}
+	exp := []earcut.Part{{Start: 0, End: 10, Holes: []int{5}}, {Start: 10, End: 15}}
+	for i, part := range exp {
+		got := g.Polygons[i]
+		if got.Start != part.Start || got.End != part.End || len(got.Holes) != len(part.Holes) {
+			t.Errorf("Polygon %d doesn't match: %v", i, got)
+		}
+	}
+	tri, ranges, err := g.Triangulate()
+	if err != nil {
+		t.Error("Error triangulating multipolygon:", err)
+	}
+	if len(tri) != 30 || len(ranges) != 2 {
+		t.Errorf("Expected 30 indices in 2 ranges, got %d in %d", len(tri), len(ranges))
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	for _, src := range []string{"POLYGON EMPTY", "MULTIPOLYGON Z EMPTY"} {
+		g, err := Parse(src)
+		if err != nil {
+			t.Errorf("Error parsing %s: %s", src, err)
+			continue
+		}
+		if len(g.Data) != 0 || len(g.Polygons) != 0 {
+			t.Errorf("Expected empty geometry for %s", src)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+		col  int
+	}{
+		{"POINT (0 0)", 1, 1},
+		{"POLYGON ((0 0, 1 0, 0 1, 0 0)", 1, 30},
+		{"POLYGON ((0 0, 1 0 0, 0 1, 0 0))", 1, 16},
+		{"POLYGON Z ((0 0, 1 0, 0 1, 0 0))", 1, 13},
+		{"POLYGON ((0 0, 1 0, 0 1, 0 0)) x", 1, 32},
+		{"POLYGON (\n  (0 0, 1 0, 0 1, 0 0),\n  (0 0, a)\n)", 3, 9},
+		{"POLYGON ((0 0, 1 0, 0 1, 0 0)) ;", 1, 32},
+		{"POLYGON ((0 0, 1 0, 0 1, 0 0) $", 1, 31},
+		{"POLYGON ((0 0, 1 0, 0 1, 0 -))", 1, 28},
+		{"POLYGONX ((0 0, 1 0, 0 1, 0 0))", 1, 1},
+		{"POLYGONM M ((0 0 1, 1 0 1, 0 1 1, 0 0 1))", 1, 10},
+		// columns count characters, not bytes
+		{"POLYGON ((0 0, 1 0, 0 1, 0 0)) é", 1, 32},
+		{"POLYGON ((0 0, 1 0, 0 1, 0 0))\u00a0x", 1, 32},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.src)
+		if err == nil {
+			t.Errorf("Expected error parsing %q", test.src)
+			continue
+		}
+		serr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Expected *SyntaxError parsing %q, got %T", test.src, err)
+			continue
+		}
+		if serr.Line != test.line || serr.Column != test.col {
+			t.Errorf("Expected error at %d:%d parsing %q, got %s", test.line, test.col, test.src, serr)
+		}
+	}
+}
+
+func TestTriangulateDegenerateExterior(t *testing.T) {
+	g, err := Parse("MULTIPOLYGON(((0 0,1 0,0 1,0 0)),((0 0,4 0,0 0),(1 1,2 1,2 2,1 1)))")
+	if err != nil {
+		t.Fatal("Error parsing multipolygon:", err)
+	}
+	if _, _, err = g.Triangulate(); err == nil {
+		t.Error("Expected error for holes in a degenerate exterior ring")
+	}
+}